@@ -0,0 +1,150 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains a DAP client that correlates requests sent to a debug
+// adapter with the responses it sends back.
+
+package dap
+
+import (
+	"context"
+	"io"
+)
+
+// Client sends requests to a debug adapter and matches them with the
+// responses the adapter sends back. It is safe for concurrent use.
+//
+// The Seq field of every outgoing request is assigned by the client.
+// Events sent by the adapter are delivered on the channel returned by Events.
+// Reverse requests sent by the adapter, such as runInTerminal, are answered
+// with an ErrorResponse unless a handler is set with ReverseRequestHandler.
+type Client struct {
+	s      *Session
+	events chan EventMessage
+	done   chan struct{}
+}
+
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	handler          Handler
+	maxHeaderLength  int
+	maxContentLength int64
+}
+
+// ReverseRequestHandler makes the client serve the requests that the debug
+// adapter sends to it, such as runInTerminal and startDebugging, with h.
+func ReverseRequestHandler(h Handler) ClientOption {
+	return func(o *clientOptions) {
+		o.handler = h
+	}
+}
+
+// ReadLimits sets the maximum header and content lengths of the messages
+// the client reads from the debug adapter, as Session.SetReadLimits does.
+func ReadLimits(maxHeaderLength int, maxContentLength int64) ClientOption {
+	return func(o *clientOptions) {
+		o.maxHeaderLength = maxHeaderLength
+		o.maxContentLength = maxContentLength
+	}
+}
+
+// NewClient returns a client that communicates with a debug adapter over
+// rwc. It starts a goroutine that reads messages from rwc until it is closed
+// or an I/O error occurs.
+func NewClient(rwc io.ReadWriteCloser, opts ...ClientOption) *Client {
+	o := clientOptions{handler: UnimplementedHandler{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c := &Client{
+		s:      NewSession(rwc),
+		events: make(chan EventMessage),
+		done:   make(chan struct{}),
+	}
+	c.s.SetReadLimits(o.maxHeaderLength, o.maxContentLength)
+	queue := make(chan EventMessage)
+	c.s.onEvent = func(e EventMessage) { queue <- e }
+	go c.forwardEvents(queue)
+	go func() {
+		defer close(c.done)
+		c.s.Serve(o.handler)
+		close(queue)
+	}()
+	return c
+}
+
+// Session returns the session the client runs on, which can be used to
+// send messages other than requests.
+func (c *Client) Session() *Session {
+	return c.s
+}
+
+// Events returns the channel on which events received from the debug
+// adapter are delivered in the order they arrive. Events are buffered
+// internally, so a slow reader never blocks responses from being matched,
+// and none are dropped. The channel is closed once the connection ends.
+func (c *Client) Events() <-chan EventMessage {
+	return c.events
+}
+
+// Call sends req to the debug adapter and blocks until the matching response
+// arrives. See CallContext for details.
+func (c *Client) Call(req RequestMessage) (ResponseMessage, error) {
+	return c.s.Call(req)
+}
+
+// CallContext sends req to the debug adapter and blocks until the matching
+// response arrives or ctx is done. The Seq and Type fields of req are filled
+// in by the client, and so is Command if it is empty.
+//
+// If the adapter replies with an ErrorResponse, it is returned together with
+// a *ResponseError wrapping it.
+func (c *Client) CallContext(ctx context.Context, req RequestMessage) (ResponseMessage, error) {
+	return c.s.CallContext(ctx, req)
+}
+
+// Close closes the underlying connection. Calls waiting for a response
+// return ErrSessionClosed.
+func (c *Client) Close() error {
+	err := c.s.Close()
+	<-c.done
+	return err
+}
+
+// forwardEvents moves events from queue to c.events, buffering as many as
+// needed so that the read loop never blocks on a slow event reader.
+func (c *Client) forwardEvents(queue <-chan EventMessage) {
+	defer close(c.events)
+	var buf []EventMessage
+	for queue != nil || len(buf) > 0 {
+		var out chan<- EventMessage
+		var next EventMessage
+		if len(buf) > 0 {
+			out = c.events
+			next = buf[0]
+		}
+		select {
+		case e, ok := <-queue:
+			if !ok {
+				queue = nil
+				continue
+			}
+			buf = append(buf, e)
+		case out <- next:
+			buf = buf[1:]
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dap
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeAdapter reads requests from conn and answers them with reply until
+// the connection is closed.
+func fakeAdapter(t *testing.T, conn net.Conn, reply func(w *bufio.Writer, req RequestMessage)) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		msg, err := ReadProtocolMessage(r)
+		if err != nil {
+			return
+		}
+		req, ok := msg.(RequestMessage)
+		if !ok {
+			t.Errorf("adapter got %#v, want a request", msg)
+			continue
+		}
+		reply(w, req)
+		w.Flush()
+	}
+}
+
+func TestClientCall(t *testing.T) {
+	clientConn, adapterConn := net.Pipe()
+	go fakeAdapter(t, adapterConn, func(w *bufio.Writer, req RequestMessage) {
+		r := req.GetRequest()
+		switch r.Command {
+		case "threads":
+			WriteProtocolMessage(w, &OutputEvent{
+				Event: *newEvent(1, "output"),
+				Body:  OutputEventBody{Output: "hello"},
+			})
+			WriteProtocolMessage(w, &ThreadsResponse{
+				Response: *newResponse(2, r.Seq, r.Command, true),
+				Body:     ThreadsResponseBody{Threads: []Thread{{Id: 1, Name: "main"}}},
+			})
+		default:
+			er := &ErrorResponse{Response: *newResponse(3, r.Seq, r.Command, false)}
+			er.Message = "unsupported"
+			WriteProtocolMessage(w, er)
+		}
+	})
+	c := NewClient(clientConn)
+	defer c.Close()
+
+	for i := 1; i <= 2; i++ {
+		req := &ThreadsRequest{}
+		resp, err := c.Call(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.Seq != i || req.Type != "request" || req.Command != "threads" {
+			t.Errorf("got request %#v, want seq=%d type=request command=threads", req.Request, i)
+		}
+		tr, ok := resp.(*ThreadsResponse)
+		if !ok {
+			t.Fatalf("got %#v, want *ThreadsResponse", resp)
+		}
+		if len(tr.Body.Threads) != 1 || tr.Body.Threads[0].Name != "main" {
+			t.Errorf("got threads %#v, want [main]", tr.Body.Threads)
+		}
+		e := <-c.Events()
+		if oe, ok := e.(*OutputEvent); !ok || oe.Body.Output != "hello" {
+			t.Errorf("got event %#v, want output event", e)
+		}
+	}
+
+	resp, err := c.Call(&PauseRequest{Arguments: PauseArguments{ThreadId: 1}})
+	var re *ResponseError
+	if !errors.As(err, &re) {
+		t.Fatalf("got err=%v, want *ResponseError", err)
+	}
+	if re.Response != resp || re.Response.Command != "pause" || re.Error() != "pause request failed: unsupported" {
+		t.Errorf("got %#v, want ErrorResponse to pause", re.Response)
+	}
+}
+
+func TestClientCallContext(t *testing.T) {
+	clientConn, adapterConn := net.Pipe()
+	// The adapter never replies.
+	go fakeAdapter(t, adapterConn, func(*bufio.Writer, RequestMessage) {})
+	c := NewClient(clientConn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.CallContext(ctx, &ThreadsRequest{}); err != context.DeadlineExceeded {
+		t.Errorf("got err=%v, want %v", err, context.DeadlineExceeded)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Call(&ThreadsRequest{})
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	c.Close()
+	if err := <-done; err != ErrSessionClosed {
+		t.Errorf("got err=%v, want %v", err, ErrSessionClosed)
+	}
+	if _, ok := <-c.Events(); ok {
+		t.Error("got open events channel, want closed")
+	}
+	if _, err := c.Call(&ThreadsRequest{}); err != ErrSessionClosed {
+		t.Errorf("got err=%v, want %v", err, ErrSessionClosed)
+	}
+}
@@ -37,6 +37,7 @@ import (
 var (
 	uFlag = flag.Bool("u", false, "updates the debugProtocol.json file before generating the code")
 	oFlag = flag.String("o", "", "specifies the output file name. If unspecified, outputs to stdout")
+	pFlag = flag.Bool("p", false, "emits pointers for optional properties of non-struct types, to tell absent properties from zero values")
 )
 
 // parseRef parses the value of a "$ref" key.
@@ -137,6 +138,7 @@ func parsePropertyType(propValue map[string]any) string {
 
 	case []any:
 		// This field is polymorphic so it needs a generic type.
+		var types []string
 		for _, el := range typ {
 			s, ok := el.(string)
 			if !ok {
@@ -146,8 +148,16 @@ func parsePropertyType(propValue map[string]any) string {
 				// It contains non-fundamental types, so treat it as opaque.
 				return "json.RawMessage"
 			}
+			types = append(types, s)
 		}
-		// The possible types are all fundamental types, so we can use any.
+		// Some combinations have union types, defined in union.go.
+		switch strings.Join(types, ",") {
+		case "integer,string":
+			return "IntOrString"
+		case "string,null":
+			return "NullableString"
+		}
+		// The other possible types are all fundamental types, so we can use any.
 		return "any"
 
 	default:
@@ -235,6 +245,13 @@ func emitToplevelType(typeName string, descJson json.RawMessage, goTypeIsStruct
 
 	if descTypeString == "string" {
 		fmt.Fprintf(&b, "type %s string\n", typeName)
+		var desc map[string]any
+		if err := json.Unmarshal(descJson, &desc); err != nil {
+			log.Fatal(err)
+		}
+		if values, closed := parseEnum(desc); values != nil {
+			emitEnumConstants(&b, typeName, values, closed)
+		}
 		return b.String()
 	} else if descTypeString == "object" {
 		fmt.Fprintf(&b, "type %s struct {\n", typeName)
@@ -264,13 +281,14 @@ func emitToplevelType(typeName string, descJson json.RawMessage, goTypeIsStruct
 	requiredMap := make(map[string]bool)
 
 	if requiredJson, ok := descMap["required"]; ok {
-		var required []any
+		var required []string
 		if err := json.Unmarshal(requiredJson, &required); err != nil {
 			log.Fatal(err)
 		}
 		for _, r := range required {
-			requiredMap[r.(string)] = true
+			requiredMap[r] = true
 		}
+		requiredProperties = append(requiredProperties, typeRequiredProperties{typeName, required})
 	}
 
 	// Some types will have a "body" which should be emitted as a separate type.
@@ -279,6 +297,14 @@ func emitToplevelType(typeName string, descJson json.RawMessage, goTypeIsStruct
 	// done.
 	bodyType := ""
 
+	// Accessors of the optional properties emitted as pointers because of
+	// the -p flag, which are emitted after the current type.
+	var accessors strings.Builder
+
+	// Constants of the properties that are enums, which are emitted after
+	// the current type.
+	var enumConstants strings.Builder
+
 	for _, propName := range propsNamesInOrder {
 		// The JSON schema is designed for the TypeScript type system, where a
 		// subclass can redefine a field in a superclass with a refined type (such
@@ -316,6 +342,7 @@ func emitToplevelType(typeName string, descJson json.RawMessage, goTypeIsStruct
 				bodyType = emitToplevelType(bodyTypeName, propsMapOfJson["body"], goTypeIsStruct)
 			}
 
+			payloadTypes[typeName] = bodyTypeName
 			if requiredMap["body"] {
 				fmt.Fprintf(&b, "\t%s %s `json:\"body\"`\n", "Body", bodyTypeName)
 			} else {
@@ -325,9 +352,11 @@ func emitToplevelType(typeName string, descJson json.RawMessage, goTypeIsStruct
 			// Special case for LaunchRequest or AttachRequest arguments, which are implementation
 			// defined and don't have pre-set field names in the specification.
 			fmt.Fprintln(&b, "\tArguments json.RawMessage `json:\"arguments\"`")
+			payloadTypes[typeName] = "json.RawMessage"
 		} else {
 			// Go type of this property.
 			goType := parsePropertyType(propDesc)
+			emitPropertyEnum(&enumConstants, typeName, propName, propDesc)
 
 			jsonTag := fmt.Sprintf("`json:\"%s", propName)
 			if requiredMap[propName] {
@@ -360,13 +389,29 @@ func emitToplevelType(typeName string, descJson json.RawMessage, goTypeIsStruct
 						goType = "*" + goType
 					}
 				}
+				// The same goes for union types, which are structs as well.
+				if goType == "IntOrString" || goType == "NullableString" {
+					goType = "*" + goType
+				}
+				// With -p, the same goes for numbers and booleans, so that 0 and false
+				// can be told apart from absent values, and be sent. An empty string
+				// is not a meaningful value of any property.
+				if *pFlag && (goType == "int" || goType == "bool") {
+					emitAccessor(&accessors, typeName, goFieldName(propName), goType)
+					goType = "*" + goType
+				}
 			}
 			fmt.Fprintf(&b, "\t%s %s %s\n", goFieldName(propName), goType, jsonTag)
+			if propName == "arguments" {
+				payloadTypes[typeName] = goType
+			}
 
 		}
 	}
 
 	b.WriteString("}\n")
+	b.WriteString(accessors.String())
+	b.WriteString(enumConstants.String())
 
 	if len(bodyType) > 0 {
 		b.WriteString("\n")
@@ -376,6 +421,134 @@ func emitToplevelType(typeName string, descJson json.RawMessage, goTypeIsStruct
 	return b.String()
 }
 
+// parseEnum returns the values of the enum described by propValue, and
+// whether the enum is closed ("enum") rather than open ("_enum"), or nil if
+// propValue does not describe an enum.
+func parseEnum(propValue map[string]any) (values []string, closed bool) {
+	enum, closed := propValue["enum"]
+	if !closed {
+		if enum = propValue["_enum"]; enum == nil {
+			return nil, false
+		}
+	}
+	for _, v := range enum.([]any) {
+		values = append(values, v.(string))
+	}
+	return values, closed
+}
+
+// emitPropertyEnum emits an untyped constant for each value of property
+// propName of typeName, if it is an enum or an array of enums. The property
+// keeps its string type, so that values beyond those of the specification,
+// which open enums allow, can be set and code written for plain strings
+// keeps working.
+func emitPropertyEnum(b *strings.Builder, typeName, propName string, propDesc map[string]any) {
+	if typeName == "ProtocolMessage" && propName == "type" {
+		// The type of messages is handled by the Go types themselves.
+		return
+	}
+	desc := propDesc
+	if propDesc["type"] == "array" {
+		desc = propDesc["items"].(map[string]any)
+	}
+	values, _ := parseEnum(desc)
+	if values == nil {
+		return
+	}
+	prefix := typeName + goFieldName(propName)
+	fmt.Fprintf(b, "\n// Values of %s.%s.\nconst (\n", typeName, goFieldName(propName))
+	for _, v := range values {
+		fmt.Fprintf(b, "\t%s = %q\n", enumConstantName(prefix, v), v)
+	}
+	b.WriteString(")\n")
+}
+
+// emitEnumConstants emits a constant of type typeName for each of values,
+// and if the enum is closed, an IsValid method.
+func emitEnumConstants(b *strings.Builder, typeName string, values []string, closed bool) {
+	b.WriteString("\nconst (\n")
+	for _, v := range values {
+		fmt.Fprintf(b, "\t%s %s = %q\n", enumConstantName(typeName, v), typeName, v)
+	}
+	b.WriteString(")\n")
+	if !closed {
+		return
+	}
+	recv := strings.ToLower(typeName[:1])
+	fmt.Fprintf(b, "\n// IsValid reports whether %s is one of the values allowed by the specification.\n", recv)
+	fmt.Fprintf(b, "func (%s %s) IsValid() bool {\n\tswitch %s {\n\tcase ", recv, typeName, recv)
+	for i, v := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(enumConstantName(typeName, v))
+	}
+	b.WriteString(":\n\t\treturn true\n\t}\n\treturn false\n}\n")
+}
+
+// enumConstantName returns the name of the constant for value of the enum
+// typeName. For example, "SteppingGranularity" and "statement" =>
+// "SteppingGranularityStatement", and "StoppedEventBodyReason" and
+// "function breakpoint" => "StoppedEventBodyReasonFunctionBreakpoint".
+func enumConstantName(typeName, value string) string {
+	var ret strings.Builder
+	ret.WriteString(typeName)
+	for _, word := range strings.Fields(value) {
+		r := []rune(word)
+		ret.WriteRune(unicode.ToUpper(r[0]))
+		ret.WriteString(string(r[1:]))
+	}
+	return ret.String()
+}
+
+// emitAccessor emits a method of typeName that returns the value of the
+// optional field fieldName of type goType, emitted as a pointer, or the zero
+// value of goType if the field is absent.
+func emitAccessor(b *strings.Builder, typeName, fieldName, goType string) {
+	recv := strings.ToLower(typeName[:1])
+	fmt.Fprintf(b, "\n// Get%s returns the value of %s, or the zero value if it is absent.\n", fieldName, fieldName)
+	fmt.Fprintf(b, "func (%s *%s) Get%s() %s {\n", recv, typeName, fieldName, goType)
+	fmt.Fprintf(b, "\tif %s == nil || %s.%s == nil {\n\t\tvar zero %s\n\t\treturn zero\n\t}\n", recv, recv, fieldName, goType)
+	fmt.Fprintf(b, "\treturn *%s.%s\n}\n", recv, fieldName)
+}
+
+// payloadTypes maps the names of the request, response and event types
+// emitted by emitToplevelType to the Go types of their arguments or body, if
+// they have any.
+var payloadTypes = make(map[string]string)
+
+// typeRequiredProperties holds the names of the properties of a type that
+// the specification marks as required.
+type typeRequiredProperties struct {
+	typeName   string
+	properties []string
+}
+
+// requiredProperties accumulates the required properties of the types
+// emitted by emitToplevelType, in the order they are emitted.
+var requiredProperties []typeRequiredProperties
+
+// emitRequiredProperties emits a map from the name of every type with
+// required properties to the names of those properties, which is used by
+// the strict decoding mode of Codec.
+func emitRequiredProperties(sb *strings.Builder) {
+	fmt.Fprint(sb, `
+// Mapping of type names and the JSON names of the properties of the type
+// that are required by the specification.
+var requiredProperties = map[string][]string{`)
+	for _, t := range requiredProperties {
+		fmt.Fprintf(sb, "\n\t%q:\t{", t.typeName)
+		for i, p := range t.properties {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(sb, "%q", p)
+		}
+		sb.WriteString("},")
+	}
+	fmt.Fprint(sb, "\n}\n")
+}
+
 // keysInOrder returns the keys in json object in b, in their original order.
 // Based on https://github.com/golang/go/issues/27179#issuecomment-415559968
 func keysInOrder(b []byte) ([]string, error) {
@@ -512,6 +685,147 @@ var eventCtor = map[string]messageCtor{`)
 	fmt.Fprint(sb, "\n}\n")
 }
 
+// emitNames emits constants for the commands of reqs and the events of
+// events.
+func emitNames(sb *strings.Builder, reqs, events []string) {
+	fmt.Fprint(sb, `
+// Commands of the requests and responses defined by the specification.
+const (`)
+	for _, r := range reqs {
+		name := strings.TrimSuffix(r, "Request")
+		fmt.Fprintf(sb, "\n\tCommand%s = %q", name, firstToLower(name))
+	}
+	fmt.Fprint(sb, `
+)
+
+// Events defined by the specification.
+const (`)
+	for _, e := range events {
+		name := strings.TrimSuffix(e, "Event")
+		fmt.Fprintf(sb, "\n\tEvent%s = %q", name, firstToLower(name))
+	}
+	fmt.Fprint(sb, "\n)\n")
+}
+
+// emitConstructors emits a constructor for each of reqs, resps and events,
+// which fills in the fields of the message that follow from its type and,
+// for responses, from the request being answered.
+func emitConstructors(sb *strings.Builder, reqs, resps, events []string) {
+	fmt.Fprint(sb, `
+// Constructors of messages, which leave Seq for the sender, such as a
+// SeqWriter or a Session, to set.
+`)
+	for _, r := range reqs {
+		name := strings.TrimSuffix(r, "Request")
+		param, field := payloadParam("arguments", "Arguments", payloadTypes[r])
+		fmt.Fprintf(sb, "\n// New%s returns %s request%s.\n", r, withArticle(firstToLower(name)), withPayload(param))
+		fmt.Fprintf(sb, "func New%s(%s) *%s {\n", r, param, r)
+		fmt.Fprintf(sb, "\treturn &%s{\n\t\tRequest: Request{ProtocolMessage: ProtocolMessage{Type: \"request\"}, Command: Command%s},\n%s\t}\n}\n", r, name, field)
+	}
+	for _, r := range resps {
+		name := strings.TrimSuffix(r, "Response")
+		param, field := payloadParam("body", "Body", payloadTypes[r])
+		if param != "" {
+			param = ", " + param
+		}
+		fmt.Fprintf(sb, "\n// New%s returns a successful response to req%s.\n", r, withPayload(param))
+		fmt.Fprintf(sb, "func New%s(req *%sRequest%s) *%s {\n", r, name, param, r)
+		fmt.Fprintf(sb, "\treturn &%s{\n\t\tResponse: Response{ProtocolMessage: ProtocolMessage{Type: \"response\"}, Command: Command%s, RequestSeq: req.Seq, Success: true},\n%s\t}\n}\n", r, name, field)
+	}
+	for _, e := range events {
+		name := strings.TrimSuffix(e, "Event")
+		param, field := payloadParam("body", "Body", payloadTypes[e])
+		fmt.Fprintf(sb, "\n// New%s returns %s event%s.\n", e, withArticle(firstToLower(name)), withPayload(param))
+		fmt.Fprintf(sb, "func New%s(%s) *%s {\n", e, param, e)
+		fmt.Fprintf(sb, "\treturn &%s{\n\t\tEvent: Event{ProtocolMessage: ProtocolMessage{Type: \"event\"}, Event: Event%s},\n%s\t}\n}\n", e, name, field)
+	}
+}
+
+// emitHandler emits the Handler interface, with one method per request of
+// reqs, the methods of UnimplementedHandler and HandlerFunc, and the
+// dispatchRequest function that calls the method handling a request.
+func emitHandler(sb *strings.Builder, reqs []string) {
+	fmt.Fprint(sb, `
+// Handler handles the requests received by a Session, with one method per
+// request type defined by the specification.
+//
+// Each method is called in its own goroutine, so requests can be processed
+// concurrently. A method returns the response to send back; the Session fills
+// in its Seq, Type, RequestSeq, Command and Success fields, so only the body
+// needs to be populated. A nil response results in an empty successful
+// response, and a non-nil error results in an ErrorResponse.
+//
+// Implementations should embed UnimplementedHandler, so that they only need
+// to implement the requests they support.
+type Handler interface {`)
+	for _, r := range reqs {
+		name := strings.TrimSuffix(r, "Request")
+		fmt.Fprintf(sb, "\n\tOn%s(ctx context.Context, req *%s) (*%sResponse, error)", r, r, name)
+	}
+	fmt.Fprint(sb, "\n}\n")
+
+	for _, r := range reqs {
+		name := strings.TrimSuffix(r, "Request")
+		result := "nil, unsupported(req)"
+		if r == "CancelRequest" {
+			// Cancel requests are carried out by the Session.
+			result = "nil, nil"
+		}
+		fmt.Fprintf(sb, "\nfunc (UnimplementedHandler) On%s(ctx context.Context, req *%s) (*%sResponse, error) {\n\treturn %s\n}\n", r, r, name, result)
+	}
+
+	for _, r := range reqs {
+		name := strings.TrimSuffix(r, "Request")
+		fmt.Fprintf(sb, "\nfunc (f HandlerFunc) On%s(ctx context.Context, req *%s) (*%sResponse, error) {\n\treturn callHandlerFunc[%sResponse](ctx, f, req)\n}\n", r, r, name, name)
+	}
+
+	fmt.Fprint(sb, `
+// dispatchRequest calls the method of h that handles req and returns the
+// resulting response. Requests that are not defined by the specification
+// are rejected with ErrUnsupportedRequest.
+func dispatchRequest(ctx context.Context, h Handler, req RequestMessage) (ResponseMessage, error) {
+	switch req := req.(type) {`)
+	for _, r := range reqs {
+		fmt.Fprintf(sb, "\n\tcase *%s:\n\t\treturn handle(ctx, req, h.On%s)", r, r)
+	}
+	fmt.Fprint(sb, `
+	default:
+		return nil, unsupported(req)
+	}
+}
+`)
+}
+
+// payloadParam returns the constructor parameter and the struct field
+// initialization for a payload of type goType, or empty strings if there
+// is no payload.
+func payloadParam(param, field, goType string) (string, string) {
+	if goType == "" {
+		return "", ""
+	}
+	return param + " " + goType, fmt.Sprintf("\t\t%s: %s,\n", field, param)
+}
+
+// withArticle returns s preceded by the indefinite article.
+func withArticle(s string) string {
+	if strings.ContainsRune("aeiou", rune(s[0])) {
+		return "an " + s
+	}
+	return "a " + s
+}
+
+// withPayload returns the part of the documentation of a constructor that
+// describes its payload parameter.
+func withPayload(param string) string {
+	switch {
+	case strings.Contains(param, "arguments"):
+		return " with arguments"
+	case strings.Contains(param, "body"):
+		return " with body"
+	}
+	return ""
+}
+
 func firstToLower(s string) string {
 	r := []rune(s)
 	return string(unicode.ToLower(r[0])) + string(r[1:])
@@ -537,7 +851,10 @@ const preamble = `// Copyright 2020 Google LLC
 
 package dap
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+)
 
 // Message is an interface that all DAP message types implement with pointer
 // receivers. It's not part of the protocol but is used to enforce static
@@ -581,6 +898,16 @@ type LaunchAttachRequest interface {
 }
 `
 
+// ptrFunc is emitted with the -p flag, to help set optional properties.
+const ptrFunc = `
+// Ptr returns a pointer to v, such as to set an optional property:
+//
+//	args := StackTraceArguments{ThreadId: 1, Levels: Ptr(0)}
+func Ptr[T any](v T) *T {
+	return &v
+}
+`
+
 // typesExcludeList is an exclude list of type names we don't want to emit.
 var typesExcludeList = map[string]bool{
 	// LaunchRequest and AttachRequest arguments can be arbitrary maps.
@@ -646,6 +973,9 @@ func main() {
 
 	var b strings.Builder
 	b.WriteString(preamble)
+	if *pFlag {
+		b.WriteString(ptrFunc)
+	}
 
 	typeNames, err := keysInOrder(m["definitions"])
 	if err != nil {
@@ -674,6 +1004,10 @@ func main() {
 
 	// Emit the maps from id to response and event types.
 	emitCtor(&b, requests, responses, events)
+	emitNames(&b, requests, events)
+	emitConstructors(&b, requests, responses, events)
+	emitHandler(&b, requests)
+	emitRequiredProperties(&b)
 
 	wholeFile := []byte(b.String())
 	formatted, err := format.Source(wholeFile)
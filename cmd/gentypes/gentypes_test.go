@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestPointers builds the dap package with types generated with -p and runs
+// testdata/pointers_test.go against them, as the tests of the package are
+// written for the default types. So are error.go and progress.go, which
+// set optional properties, and testdata/pointers_shims.go replaces them.
+func TestPointers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	dir := t.TempDir()
+	files, err := filepath.Glob("../../*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, "../../go.mod", "testdata/pointers_test.go", "testdata/pointers_shims.go")
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") && !strings.HasPrefix(f, "testdata") {
+			continue
+		}
+		if base := filepath.Base(f); base == "error.go" || base == "progress.go" {
+			continue
+		}
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(f)), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	run(".", "run", ".", "-p", "-o", filepath.Join(dir, "schematypes.go"), "debugProtocol.json")
+	run(dir, "test", ".")
+}
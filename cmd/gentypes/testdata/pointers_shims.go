@@ -0,0 +1,31 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file is copied next to types generated with -p by TestPointers in
+// cmd/gentypes, in place of error.go and progress.go, which are written for
+// the default types. It declares what the rest of the package needs of
+// them.
+
+package dap
+
+type Error struct{}
+
+func (e *Error) Error() string               { return "" }
+func (e *Error) ErrorMessage() *ErrorMessage { return nil }
+
+func ErrorFromResponse(er *ErrorResponse) *Error { return nil }
+
+type Progress struct{ cancellable bool }
+
+func (p *Progress) cancel() {}
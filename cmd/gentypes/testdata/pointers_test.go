@@ -0,0 +1,63 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file is copied next to types generated with -p by TestPointers in
+// cmd/gentypes, which runs the test below against them.
+
+package dap
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOptionalPointers(t *testing.T) {
+	// Zero values are sent, and absent properties are omitted.
+	e := &ContinuedEvent{Body: ContinuedEventBody{ThreadId: 1, AllThreadsContinued: Ptr(false)}}
+	b, err := json.Marshal(e.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"threadId":1,"allThreadsContinued":false}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	b, err = json.Marshal(StackTraceArguments{ThreadId: 1, Levels: Ptr(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"threadId":1,"levels":0}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// They stay so once decoded.
+	msg, err := DecodeProtocolMessage([]byte(`{"seq":1,"type":"request","command":"stackTrace","arguments":{"threadId":1,"levels":0}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := msg.(*StackTraceRequest).Arguments
+	if args.Levels == nil || *args.Levels != 0 || args.GetLevels() != 0 {
+		t.Errorf("got levels %v, want 0", args.Levels)
+	}
+	if args.StartFrame != nil || args.GetStartFrame() != 0 {
+		t.Errorf("got startFrame %v, want absent", args.StartFrame)
+	}
+	msg, err = DecodeProtocolMessage([]byte(`{"seq":2,"type":"event","event":"continued","body":{"threadId":1,"allThreadsContinued":false}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := msg.(*ContinuedEvent).Body
+	if body.AllThreadsContinued == nil || *body.AllThreadsContinued {
+		t.Errorf("got allThreadsContinued %v, want false", body.AllThreadsContinued)
+	}
+}
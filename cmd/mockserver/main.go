@@ -14,19 +14,105 @@
 
 // This starts a mock DAP server that runs indefinitely, accepts DAP
 // requests and responds with dummy or error responses.
+//
+// By default, the server listens on a TCP port. With -stdio, it instead
+// serves a single client on its standard input and output, the way IDEs
+// launch debug adapters as child processes, and exits once the input is
+// closed. With -unix or -listen, it listens on a Unix domain socket or on
+// any address supported by net.Listen. Logs are written to the standard
+// error, or to the file given with -log, so that they never mix with the
+// protocol stream. With -scenario, the fake debugger replays the program
+// described by a JSON scenario file instead of the default one, or
+// simulates the program that the scenario describes; see scenario.go and
+// program.go for its format.
 
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log"
+	"net"
+	"os"
+	"strings"
 )
 
 func main() {
 	port := flag.String("port", "54321", "TCP port to listen on")
+	stdio := flag.Bool("stdio", false, "serve a single client on stdin and stdout instead of listening")
+	unixSocket := flag.String("unix", "", "path of a Unix domain socket to listen on instead of the TCP port")
+	listen := flag.String("listen", "", "`network:address` to listen on instead of the TCP port, such as tcp:localhost:4711 or unix:/tmp/dap.sock")
+	logFile := flag.String("log", "", "file to append logs to instead of stderr")
+	scenarioFile := flag.String("scenario", "", "JSON `file` describing the threads, stack frames, scopes, variables, sources, stops and output of the debugged program, or a program to simulate")
 	flag.Parse()
-	err := server(*port)
+
+	if *logFile != "" {
+		f, err := os.OpenFile(*logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			log.Fatal("Could not open log file: ", err)
+		}
+		defer f.Close()
+		log.SetOutput(f)
+	}
+
+	modes := 0
+	for _, set := range []bool{*stdio, *unixSocket != "", *listen != ""} {
+		if set {
+			modes++
+		}
+	}
+	if modes > 1 {
+		log.Fatal("Only one of -stdio, -unix and -listen may be set")
+	}
+
+	sc := defaultScenario
+	if *scenarioFile != "" {
+		var err error
+		if sc, err = loadScenario(*scenarioFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *stdio {
+		log.Println("Serving on stdin and stdout")
+		handleConnection(stdioConn{os.Stdin, os.Stdout}, sc)
+		return
+	}
+
+	network, address := "tcp", ":"+*port
+	switch {
+	case *unixSocket != "":
+		network, address = "unix", *unixSocket
+	case *listen != "":
+		var err error
+		if network, address, err = parseListenAddress(*listen); err != nil {
+			log.Fatal(err)
+		}
+	}
+	listener, err := net.Listen(network, address)
 	if err != nil {
 		log.Fatal("Could not start server: ", err)
 	}
+	if err := serve(listener, sc); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Fatal("Server failed: ", err)
+	}
+}
+
+// parseListenAddress splits the value of the -listen flag into a network
+// and an address for net.Listen.
+func parseListenAddress(s string) (network, address string, err error) {
+	network, address, ok := strings.Cut(s, ":")
+	if !ok || network == "" || address == "" {
+		return "", "", fmt.Errorf("invalid -listen value %q, want network:address", s)
+	}
+	return network, address, nil
+}
+
+// stdioConn is the connection to a client on the standard input and output.
+// Closing it closes the output.
+type stdioConn struct {
+	io.Reader
+	io.WriteCloser
 }
@@ -0,0 +1,548 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file defines simulated programs, which the fake debugger runs
+// statement by statement instead of replaying the stops of a scenario.
+// A program is given by the "program" property of a scenario:
+//
+//	"program": {
+//	  "source": {"name": "main.go", "path": "/src/main.go"},
+//	  "threads": [
+//	    {"id": 1, "name": "main", "function": "main.main"},
+//	    {"id": 2, "name": "worker", "function": "main.worker"}
+//	  ],
+//	  "functions": [
+//	    {"name": "main.main", "statements": [
+//	      {"line": 4, "set": {"n": "3"}},
+//	      {"line": 5, "call": "main.square"},
+//	      {"line": 6, "output": "done\n"}
+//	    ]},
+//	    {"name": "main.square", "statements": [
+//	      {"line": 10, "set": {"r": "9"}},
+//	      {"line": 11}
+//	    ]},
+//	    {"name": "main.worker", "statements": [
+//	      {"line": 15, "call": "main.square", "goto": 15}
+//	    ]}
+//	  ]
+//	}
+//
+// Each thread runs the statements of its function in order. A statement
+// sets local variables of its function, writes output, calls another
+// function, whose statements run before the next statement of the caller,
+// and then jumps to the statement at line goto if set, which makes loops.
+// A function returns once its last statement has run, and a thread exits
+// once its function returns. Once every thread has exited, the program
+// exits with the exit code of the scenario.
+//
+// While the program runs, every thread runs one statement in turn, and
+// the threads stop as soon as one of them reaches a line with a
+// breakpoint, or completes a step, or is paused. Stepping works as in a
+// real debugger: next runs over calls, stepIn stops at the first
+// statement of a callee and stepOut runs until the function returns.
+// A stopped thread can also go to another statement of its function, or
+// restart one of its frames, but the program cannot run backwards.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/go-dap"
+)
+
+// program is a simulated program. Like a scenario, it is not modified
+// once loaded.
+type program struct {
+	// Source is the source file that holds every statement.
+	Source    dap.Source        `json:"source"`
+	Threads   []programThread   `json:"threads"`
+	Functions []programFunction `json:"functions"`
+
+	// functions maps the names of the functions to them.
+	functions map[string]*programFunction
+}
+
+// programThread is a thread of a program, which runs Function.
+type programThread struct {
+	dap.Thread
+	Function string `json:"function"`
+}
+
+// programFunction is a function of a program.
+type programFunction struct {
+	Name       string             `json:"name"`
+	Statements []programStatement `json:"statements"`
+}
+
+// programStatement is a statement of a function, which takes up a line.
+type programStatement struct {
+	Line int `json:"line"`
+	// Set assigns values to local variables of the function.
+	Set map[string]string `json:"set"`
+	// Output is written to stdout.
+	Output string `json:"output"`
+	// Call is the name of the function that the statement calls.
+	Call string `json:"call"`
+	// Goto is the line of the statement of the same function that runs
+	// next, rather than the following one, if not zero.
+	Goto int `json:"goto"`
+}
+
+// check reports whether p is a valid program, and indexes its functions.
+func (p *program) check() error {
+	if len(p.Threads) == 0 {
+		return fmt.Errorf("program has no threads")
+	}
+	p.functions = make(map[string]*programFunction)
+	for i := range p.Functions {
+		f := &p.Functions[i]
+		if _, ok := p.functions[f.Name]; ok {
+			return fmt.Errorf("duplicate function %q", f.Name)
+		}
+		if len(f.Statements) == 0 {
+			return fmt.Errorf("function %q has no statements", f.Name)
+		}
+		p.functions[f.Name] = f
+	}
+	for _, f := range p.Functions {
+		for i, s := range f.Statements {
+			if s.Line <= 0 {
+				return fmt.Errorf("statement %d of function %q has no line", i, f.Name)
+			}
+			if _, ok := p.functions[s.Call]; s.Call != "" && !ok {
+				return fmt.Errorf("function %q calls unknown function %q", f.Name, s.Call)
+			}
+			if s.Goto != 0 && f.index(s.Goto) < 0 {
+				return fmt.Errorf("function %q jumps to line %d, which has no statement", f.Name, s.Goto)
+			}
+		}
+	}
+	for _, t := range p.Threads {
+		if _, ok := p.functions[t.Function]; !ok {
+			return fmt.Errorf("thread %d runs unknown function %q", t.Id, t.Function)
+		}
+	}
+	// Stacks could grow without bound with recursive calls.
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var visit func(f *programFunction) error
+	visit = func(f *programFunction) error {
+		state[f.Name] = visiting
+		for _, s := range f.Statements {
+			if s.Call == "" {
+				continue
+			}
+			switch state[s.Call] {
+			case visiting:
+				return fmt.Errorf("function %q calls %q recursively", f.Name, s.Call)
+			case 0:
+				if err := visit(p.functions[s.Call]); err != nil {
+					return err
+				}
+			}
+		}
+		state[f.Name] = visited
+		return nil
+	}
+	for i := range p.Functions {
+		if state[p.Functions[i].Name] == 0 {
+			if err := visit(&p.Functions[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// index returns the index of the first statement of f at line, or -1 if
+// there is none.
+func (f *programFunction) index(line int) int {
+	for i, s := range f.Statements {
+		if s.Line == line {
+			return i
+		}
+	}
+	return -1
+}
+
+// lines returns the sorted lines of the statements of p, without
+// duplicates.
+func (p *program) lines() []int {
+	seen := make(map[int]bool)
+	var lines []int
+	for _, f := range p.Functions {
+		for _, s := range f.Statements {
+			if !seen[s.Line] {
+				seen[s.Line] = true
+				lines = append(lines, s.Line)
+			}
+		}
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// isSource reports whether s refers to the source of p.
+func (p *program) isSource(s dap.Source) bool {
+	switch {
+	case p.Source.SourceReference != 0:
+		return s.SourceReference == p.Source.SourceReference
+	case p.Source.Path != "":
+		return s.Path == p.Source.Path
+	default:
+		return s.Name == p.Source.Name
+	}
+}
+
+// stepKind is how a thread steps.
+type stepKind int
+
+const (
+	stepNone stepKind = iota // not stepping
+	stepIn
+	stepOver
+	stepOut
+)
+
+// process is a run of a program. Its methods are not safe to use
+// concurrently.
+type process struct {
+	prog *program
+	// threads are the threads that have not exited, in the order of the
+	// program.
+	threads []*threadState
+	// breakpoints maps the lines with a breakpoint to its id.
+	breakpoints      map[int]int
+	nextBreakpointId int
+	nextFrameId      int
+
+	// running is whether the threads run, until they stop. Only the
+	// thread with id singleThread runs if it is not zero.
+	running      bool
+	singleThread int
+	// stepping is the thread that steps, if any, and depth the size of
+	// its stack when the step started.
+	stepping *threadState
+	step     stepKind
+	depth    int
+	// exited is whether every thread has exited.
+	exited bool
+}
+
+// threadState is the state of a thread of a process.
+type threadState struct {
+	id   int
+	name string
+	// stack holds the frames of the thread, innermost last.
+	stack []*frameState
+}
+
+// frameState is the state of a function call.
+type frameState struct {
+	id int
+	fn *programFunction
+	// pc is the index of the statement that runs next, or that runs
+	// the call of the frame above.
+	pc     int
+	locals map[string]string
+}
+
+// newProcess returns a process of prog, whose threads are at the first
+// statement of their function.
+func newProcess(prog *program) *process {
+	p := &process{prog: prog, breakpoints: make(map[int]int), nextBreakpointId: 1}
+	p.reset()
+	return p
+}
+
+// reset starts the process again, keeping its breakpoints.
+func (p *process) reset() {
+	p.threads = nil
+	p.nextFrameId = 1000
+	for _, t := range p.prog.Threads {
+		ts := &threadState{id: t.Id, name: t.Name}
+		p.push(ts, p.prog.functions[t.Function])
+		p.threads = append(p.threads, ts)
+	}
+	p.running, p.singleThread, p.stepping, p.exited = false, 0, nil, false
+}
+
+// terminate makes every thread exit at once.
+func (p *process) terminate() {
+	p.threads = nil
+	p.running, p.stepping, p.exited = false, nil, true
+}
+
+// push calls fn on thread t.
+func (p *process) push(t *threadState, fn *programFunction) {
+	t.stack = append(t.stack, &frameState{id: p.nextFrameId, fn: fn, locals: make(map[string]string)})
+	p.nextFrameId++
+}
+
+// top returns the innermost frame of t.
+func (t *threadState) top() *frameState {
+	return t.stack[len(t.stack)-1]
+}
+
+// statement returns the statement that runs next in f.
+func (f *frameState) statement() programStatement {
+	return f.fn.Statements[f.pc]
+}
+
+// exec runs the statement at which thread t is, and returns the events
+// that it causes.
+func (p *process) exec(t *threadState) []dap.Message {
+	var events []dap.Message
+	f := t.top()
+	s := f.statement()
+	for name, value := range s.Set {
+		f.locals[name] = value
+	}
+	if s.Output != "" {
+		events = append(events, dap.NewOutputEvent(dap.OutputEventBody{Category: dap.OutputEventBodyCategoryStdout, Output: s.Output}))
+	}
+	if s.Call != "" {
+		p.push(t, p.prog.functions[s.Call])
+		return events
+	}
+	// Move on to the next statement, returning from the functions
+	// that are done.
+	for len(t.stack) > 0 {
+		f := t.top()
+		if line := f.statement().Goto; line != 0 {
+			f.pc = f.fn.index(line)
+			return events
+		}
+		if f.pc++; f.pc < len(f.fn.Statements) {
+			return events
+		}
+		t.stack = t.stack[:len(t.stack)-1]
+	}
+	return append(events, dap.NewThreadEvent(dap.ThreadEventBody{Reason: dap.ThreadEventBodyReasonExited, ThreadId: t.id}))
+}
+
+// thread returns the thread with id, or nil if it has exited or never
+// existed.
+func (p *process) thread(id int) *threadState {
+	for _, t := range p.threads {
+		if t.id == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// frame returns the frame with id, or nil if there is none.
+func (p *process) frame(id int) *frameState {
+	for _, t := range p.threads {
+		for _, f := range t.stack {
+			if f.id == id {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// frameThread returns the thread whose stack holds the frame with id, and
+// the index of the frame in the stack, or nil if there is no such frame.
+func (p *process) frameThread(id int) (*threadState, int) {
+	for _, t := range p.threads {
+		for i, f := range t.stack {
+			if f.id == id {
+				return t, i
+			}
+		}
+	}
+	return nil, 0
+}
+
+// restartFrame makes thread t run the frame at index i of its stack again
+// from its first statement, dropping the frames that it called.
+func (p *process) restartFrame(t *threadState, i int) {
+	t.stack = t.stack[:i+1]
+	f := t.stack[i]
+	f.pc = 0
+	f.locals = make(map[string]string)
+}
+
+// resume lets the threads run, only the thread with id threadId if
+// singleThread is set. If step is not stepNone, the thread with id
+// threadId steps.
+func (p *process) resume(threadId int, singleThread bool, step stepKind) error {
+	t := p.thread(threadId)
+	if t == nil && (singleThread || step != stepNone) {
+		return mockError("No thread %d", threadId)
+	}
+	p.running = true
+	p.singleThread = 0
+	if singleThread {
+		p.singleThread = threadId
+	}
+	p.stepping, p.step = nil, stepNone
+	if step != stepNone {
+		p.stepping, p.step, p.depth = t, step, len(t.stack)
+	}
+	return nil
+}
+
+// stopped returns the event that tells that the threads stopped because
+// of thread t, and stops them.
+func (p *process) stopped(t *threadState, reason string) *dap.StoppedEvent {
+	p.running, p.stepping = false, nil
+	body := dap.StoppedEventBody{Reason: reason, ThreadId: t.id, AllThreadsStopped: true}
+	if reason == dap.StoppedEventBodyReasonBreakpoint {
+		body.HitBreakpointIds = []int{p.breakpoints[t.top().statement().Line]}
+	}
+	return dap.NewStoppedEvent(body)
+}
+
+// start returns the events that tell where the threads stop before
+// running any statement, if they stop, and lets them run otherwise.
+// A process that has been terminated does not start.
+func (p *process) start(stopOnEntry bool) []dap.Message {
+	if p.exited || len(p.threads) == 0 {
+		return nil
+	}
+	if stopOnEntry {
+		return []dap.Message{p.stopped(p.threads[0], dap.StoppedEventBodyReasonEntry)}
+	}
+	for _, t := range p.threads {
+		if _, ok := p.breakpoints[t.top().statement().Line]; ok {
+			return []dap.Message{p.stopped(t, dap.StoppedEventBodyReasonBreakpoint)}
+		}
+	}
+	p.running = true
+	return nil
+}
+
+// tick runs one statement of each thread that runs, in turn, until the
+// threads stop, and returns the events that this causes.
+func (p *process) tick(exitCode int) []dap.Message {
+	var events []dap.Message
+	for _, t := range append([]*threadState(nil), p.threads...) {
+		if !p.running {
+			break
+		}
+		if p.singleThread != 0 && t.id != p.singleThread {
+			continue
+		}
+		events = append(events, p.exec(t)...)
+		if len(t.stack) == 0 {
+			p.exitThread(t)
+			continue
+		}
+		if t == p.stepping && p.stepDone() {
+			events = append(events, p.stopped(t, dap.StoppedEventBodyReasonStep))
+		} else if _, ok := p.breakpoints[t.top().statement().Line]; ok {
+			events = append(events, p.stopped(t, dap.StoppedEventBodyReasonBreakpoint))
+		}
+	}
+	if len(p.threads) == 0 && !p.exited {
+		p.running, p.exited = false, true
+		events = append(events,
+			dap.NewExitedEvent(dap.ExitedEventBody{ExitCode: exitCode}),
+			dap.NewTerminatedEvent(dap.TerminatedEventBody{}))
+	}
+	return events
+}
+
+// stepDone reports whether the thread that steps has completed its step.
+func (p *process) stepDone() bool {
+	switch n := len(p.stepping.stack); p.step {
+	case stepOver:
+		return n <= p.depth
+	case stepOut:
+		return n < p.depth
+	}
+	return true
+}
+
+// exitThread removes thread t, which has exited. The other threads keep
+// running, including when t was the only one to run or was stepping.
+func (p *process) exitThread(t *threadState) {
+	for i, u := range p.threads {
+		if u == t {
+			p.threads = append(p.threads[:i], p.threads[i+1:]...)
+			break
+		}
+	}
+	if p.singleThread == t.id {
+		p.singleThread = 0
+	}
+	if p.stepping == t {
+		p.stepping = nil
+	}
+}
+
+// stackFrames returns the stack frames of t, innermost first.
+func (p *process) stackFrames(t *threadState) []dap.StackFrame {
+	frames := make([]dap.StackFrame, 0, len(t.stack))
+	for i := len(t.stack) - 1; i >= 0; i-- {
+		f := t.stack[i]
+		source := p.prog.Source
+		frames = append(frames, dap.StackFrame{Id: f.id, Name: f.fn.Name, Source: &source, Line: f.statement().Line, Column: 1})
+	}
+	return frames
+}
+
+// variables returns the local variables of f, sorted by name.
+func (f *frameState) variables() []dap.Variable {
+	variables := []dap.Variable{}
+	for name, value := range f.locals {
+		variables = append(variables, dap.Variable{Name: name, Value: value, EvaluateName: name})
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Name < variables[j].Name })
+	return variables
+}
+
+// setBreakpoints replaces the breakpoints with those at lines, and
+// returns them. Breakpoints at lines without a statement are not
+// verified.
+func (p *process) setBreakpoints(lines []int) []dap.Breakpoint {
+	old := p.breakpoints
+	p.breakpoints = make(map[int]int)
+	breakpoints := make([]dap.Breakpoint, len(lines))
+	for i, line := range lines {
+		breakpoints[i].Line = line
+		if p.prog.lineHasStatement(line) {
+			id, ok := old[line]
+			if !ok {
+				id = p.nextBreakpointId
+				p.nextBreakpointId++
+			}
+			p.breakpoints[line] = id
+			breakpoints[i].Id = id
+			breakpoints[i].Verified = true
+		} else {
+			breakpoints[i].Message = fmt.Sprintf("No statement at line %d", line)
+		}
+	}
+	return breakpoints
+}
+
+// lineHasStatement reports whether a statement of p is at line.
+func (p *program) lineHasStatement(line int) bool {
+	for _, f := range p.Functions {
+		if f.index(line) >= 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,339 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-dap"
+)
+
+// programClient debugs a simulated program in tests.
+type programClient struct {
+	t *testing.T
+	c *dap.Client
+}
+
+// startProgram serves a debug session of the scenario at path, and
+// returns a client for it that has launched the program with breakpoints
+// at lines.
+func startProgram(t *testing.T, path string, lines ...int) *programClient {
+	pc, sc := initializeProgram(t, path)
+	pc.call(dap.NewLaunchRequest(nil))
+	breakpoints := make([]dap.SourceBreakpoint, len(lines))
+	for i, line := range lines {
+		breakpoints[i].Line = line
+	}
+	pc.call(dap.NewSetBreakpointsRequest(dap.SetBreakpointsArguments{Source: sc.Program.Source, Breakpoints: breakpoints}))
+	pc.call(dap.NewConfigurationDoneRequest(nil))
+	for _, thread := range sc.Threads {
+		pc.expect(dap.NewThreadEvent(dap.ThreadEventBody{Reason: dap.ThreadEventBodyReasonStarted, ThreadId: thread.Id}))
+	}
+	return pc
+}
+
+// initializeProgram serves a debug session of the scenario at path, and
+// returns a client for it that has initialized the session, along with
+// the scenario.
+func initializeProgram(t *testing.T, path string) (*programClient, *scenario) {
+	sc, err := loadScenario(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return initializeScenario(t, sc), sc
+}
+
+// initializeScenario serves a debug session of sc, and returns a client
+// for it that has initialized the session.
+func initializeScenario(t *testing.T, sc *scenario) *programClient {
+	log.SetOutput(ioutil.Discard)
+	serverConn, clientConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		handleConnection(serverConn, sc)
+		close(done)
+	}()
+	pc := &programClient{t, dap.NewClient(clientConn)}
+	t.Cleanup(func() {
+		pc.c.Close()
+		<-done
+	})
+
+	pc.call(dap.NewInitializeRequest(dap.InitializeRequestArguments{AdapterID: "go"}))
+	pc.expect(dap.NewInitializedEvent())
+	return pc
+}
+
+func (pc *programClient) call(req dap.RequestMessage) dap.ResponseMessage {
+	pc.t.Helper()
+	resp, err := pc.c.Call(req)
+	if err != nil {
+		pc.t.Fatalf("%T: %v", req, err)
+	}
+	return resp
+}
+
+// expect checks that the next event is want, ignoring its Seq.
+func (pc *programClient) expect(want dap.EventMessage) {
+	pc.t.Helper()
+	got, ok := <-pc.c.Events()
+	if !ok {
+		pc.t.Fatal("connection closed, want event")
+	}
+	got.GetEvent().Seq = 0
+	if !reflect.DeepEqual(got, want) {
+		pc.t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+// expectStopped checks that the threads stop because of threadId.
+func (pc *programClient) expectStopped(reason string, threadId int, hitBreakpointIds ...int) {
+	pc.t.Helper()
+	pc.expect(dap.NewStoppedEvent(dap.StoppedEventBody{Reason: reason, ThreadId: threadId, AllThreadsStopped: true, HitBreakpointIds: hitBreakpointIds}))
+}
+
+// expectStack checks the functions and lines of the frames of threadId,
+// innermost first, and returns the frames.
+func (pc *programClient) expectStack(threadId int, want ...string) []dap.StackFrame {
+	pc.t.Helper()
+	resp := pc.call(dap.NewStackTraceRequest(dap.StackTraceArguments{ThreadId: threadId})).(*dap.StackTraceResponse)
+	var got []string
+	for _, f := range resp.Body.StackFrames {
+		got = append(got, fmt.Sprintf("%s:%d", f.Name, f.Line))
+	}
+	if !reflect.DeepEqual(got, want) {
+		pc.t.Errorf("thread %d: got stack %v, want %v", threadId, got, want)
+	}
+	return resp.Body.StackFrames
+}
+
+// evaluate returns the value of variable name in the top frame of the
+// first thread.
+func (pc *programClient) evaluate(name string) string {
+	pc.t.Helper()
+	return pc.call(dap.NewEvaluateRequest(dap.EvaluateArguments{Expression: name})).(*dap.EvaluateResponse).Body.Result
+}
+
+func TestProgram(t *testing.T) {
+	pc := startProgram(t, "testdata/program.json", 5, 7)
+	pc.expectStopped(dap.StoppedEventBodyReasonBreakpoint, 1, 1)
+	frames := pc.expectStack(1, "main.main:5")
+	pc.expectStack(2, "main.worker:15")
+
+	scopes := pc.call(dap.NewScopesRequest(dap.ScopesArguments{FrameId: frames[0].Id})).(*dap.ScopesResponse).Body.Scopes
+	if len(scopes) != 1 {
+		t.Fatalf("got scopes %#v, want Locals", scopes)
+	}
+	variables := pc.call(dap.NewVariablesRequest(dap.VariablesArguments{VariablesReference: scopes[0].VariablesReference})).(*dap.VariablesResponse).Body.Variables
+	if want := []dap.Variable{{Name: "n", Value: "3", EvaluateName: "n"}}; !reflect.DeepEqual(variables, want) {
+		t.Errorf("got variables %#v, want %#v", variables, want)
+	}
+	targets := pc.call(dap.NewStepInTargetsRequest(dap.StepInTargetsArguments{FrameId: frames[0].Id})).(*dap.StepInTargetsResponse).Body.Targets
+	if len(targets) != 1 || targets[0].Label != "main.square" {
+		t.Errorf("got step in targets %#v, want main.square", targets)
+	}
+
+	pc.call(dap.NewStepInRequest(dap.StepInArguments{ThreadId: 1}))
+	pc.expectStopped(dap.StoppedEventBodyReasonStep, 1)
+	pc.expectStack(1, "main.square:10", "main.main:5")
+	pc.call(dap.NewNextRequest(dap.NextArguments{ThreadId: 1}))
+	pc.expectStopped(dap.StoppedEventBodyReasonStep, 1)
+	pc.expectStack(1, "main.square:11", "main.main:5")
+	if got := pc.evaluate("r"); got != "9" {
+		t.Errorf("got r = %s, want 9", got)
+	}
+	pc.call(dap.NewStepOutRequest(dap.StepOutArguments{ThreadId: 1}))
+	pc.expectStopped(dap.StoppedEventBodyReasonStep, 1)
+	pc.expectStack(1, "main.main:6")
+	// The other thread has not run, as the main thread completed each
+	// step with its first statement.
+	pc.expectStack(2, "main.worker:15")
+
+	pc.call(dap.NewSetVariableRequest(dap.SetVariableArguments{VariablesReference: frames[0].Id, Name: "n", Value: "4"}))
+	if got := pc.evaluate("n"); got != "4" {
+		t.Errorf("got n = %s after setVariable, want 4", got)
+	}
+
+	pc.call(dap.NewContinueRequest(dap.ContinueArguments{ThreadId: 1}))
+	pc.expect(dap.NewOutputEvent(dap.OutputEventBody{Category: dap.OutputEventBodyCategoryStdout, Output: "done\n"}))
+	pc.expect(dap.NewThreadEvent(dap.ThreadEventBody{Reason: dap.ThreadEventBodyReasonExited, ThreadId: 1}))
+	pc.expect(dap.NewOutputEvent(dap.OutputEventBody{Category: dap.OutputEventBodyCategoryStdout, Output: "worker done\n"}))
+	pc.expect(dap.NewThreadEvent(dap.ThreadEventBody{Reason: dap.ThreadEventBodyReasonExited, ThreadId: 2}))
+	pc.expect(dap.NewExitedEvent(dap.ExitedEventBody{ExitCode: 1}))
+	pc.expect(dap.NewTerminatedEvent(dap.TerminatedEventBody{}))
+	if _, err := pc.c.Call(dap.NewNextRequest(dap.NextArguments{ThreadId: 1})); !errors.As(err, new(*dap.ResponseError)) {
+		t.Errorf("got err=%v for a step after exiting, want *dap.ResponseError", err)
+	}
+
+	// Run again, stepping over the call while the other thread runs.
+	pc.call(dap.NewRestartRequest(nil))
+	pc.expectStopped(dap.StoppedEventBodyReasonBreakpoint, 1, 1)
+	pc.call(dap.NewNextRequest(dap.NextArguments{ThreadId: 1}))
+	pc.expectStopped(dap.StoppedEventBodyReasonStep, 1)
+	pc.expectStack(1, "main.main:6")
+	pc.expectStack(2, "main.square:11", "main.worker:15")
+	pc.call(dap.NewDisconnectRequest(nil))
+}
+
+func TestProgramGotoAndRestartFrame(t *testing.T) {
+	pc := startProgram(t, "testdata/program.json", 5)
+	pc.expectStopped(dap.StoppedEventBodyReasonBreakpoint, 1, 1)
+
+	source := dap.Source{Path: "/src/main.go"}
+	for line, want := range map[int]int{4: 1, 12: 0} {
+		targets := pc.call(dap.NewGotoTargetsRequest(dap.GotoTargetsArguments{Source: source, Line: line})).(*dap.GotoTargetsResponse).Body.Targets
+		if len(targets) != want {
+			t.Errorf("line %d: got goto targets %#v, want %d", line, targets, want)
+		}
+	}
+	pc.call(dap.NewGotoRequest(dap.GotoArguments{ThreadId: 1, TargetId: 4}))
+	pc.expectStopped(dap.StoppedEventBodyReasonGoto, 1)
+	pc.expectStack(1, "main.main:4")
+
+	pc.call(dap.NewNextRequest(dap.NextArguments{ThreadId: 1}))
+	pc.expectStopped(dap.StoppedEventBodyReasonStep, 1)
+	pc.call(dap.NewStepInRequest(dap.StepInArguments{ThreadId: 1}))
+	pc.expectStopped(dap.StoppedEventBodyReasonStep, 1)
+	frames := pc.expectStack(1, "main.square:10", "main.main:5")
+	pc.call(dap.NewRestartFrameRequest(dap.RestartFrameArguments{FrameId: frames[1].Id}))
+	pc.expectStopped(dap.StoppedEventBodyReasonStep, 1)
+	pc.expectStack(1, "main.main:4")
+
+	for _, req := range []dap.RequestMessage{
+		dap.NewGotoRequest(dap.GotoArguments{ThreadId: 1, TargetId: 10}),
+		dap.NewGotoRequest(dap.GotoArguments{ThreadId: 9, TargetId: 4}),
+		dap.NewRestartFrameRequest(dap.RestartFrameArguments{FrameId: frames[0].Id}),
+		// The program cannot run backwards.
+		dap.NewStepBackRequest(dap.StepBackArguments{ThreadId: 1}),
+		dap.NewReverseContinueRequest(dap.ReverseContinueArguments{ThreadId: 1}),
+	} {
+		if _, err := pc.c.Call(req); !errors.As(err, new(*dap.ResponseError)) {
+			t.Errorf("got err=%v for %#v, want *dap.ResponseError", err, req)
+		}
+	}
+	pc.expectStack(1, "main.main:4")
+}
+
+func TestProgramBreakpoints(t *testing.T) {
+	// The program stops before running any statement.
+	pc := startProgram(t, "testdata/program.json", 4)
+	pc.expectStopped(dap.StoppedEventBodyReasonBreakpoint, 1, 1)
+
+	// Breakpoints keep their ids when they are set again.
+	source := dap.Source{Path: "/src/main.go"}
+	resp := pc.call(dap.NewSetBreakpointsRequest(dap.SetBreakpointsArguments{Source: source, Breakpoints: []dap.SourceBreakpoint{{Line: 11}, {Line: 4}, {Line: 12}}})).(*dap.SetBreakpointsResponse)
+	want := []dap.Breakpoint{{Id: 2, Verified: true, Line: 11}, {Id: 1, Verified: true, Line: 4}, {Line: 12, Message: "No statement at line 12"}}
+	if !reflect.DeepEqual(resp.Body.Breakpoints, want) {
+		t.Errorf("got breakpoints %#v, want %#v", resp.Body.Breakpoints, want)
+	}
+	resp = pc.call(dap.NewSetBreakpointsRequest(dap.SetBreakpointsArguments{Source: dap.Source{Path: "/src/other.go"}, Breakpoints: []dap.SourceBreakpoint{{Line: 11}}})).(*dap.SetBreakpointsResponse)
+	if len(resp.Body.Breakpoints) != 1 || resp.Body.Breakpoints[0].Verified {
+		t.Errorf("got breakpoints %#v in another source, want an unverified one", resp.Body.Breakpoints)
+	}
+
+	locations := pc.call(dap.NewBreakpointLocationsRequest(&dap.BreakpointLocationsArguments{Source: source, Line: 5, EndLine: 12})).(*dap.BreakpointLocationsResponse).Body.Breakpoints
+	if want := []dap.BreakpointLocation{{Line: 5}, {Line: 6}, {Line: 10}, {Line: 11}}; !reflect.DeepEqual(locations, want) {
+		t.Errorf("got breakpoint locations %#v, want %#v", locations, want)
+	}
+}
+
+func TestProgramPause(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	data := `{"program": {
+		"source": {"name": "loop.go"},
+		"threads": [{"id": 1, "name": "main", "function": "main.main"}],
+		"functions": [{"name": "main.main", "statements": [{"line": 3, "set": {"i": "0"}}, {"line": 4, "goto": 3}]}]
+	}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pc := startProgram(t, path)
+
+	// The program loops forever, until it is paused.
+	if _, err := pc.c.Call(dap.NewPauseRequest(dap.PauseArguments{ThreadId: 9})); !errors.As(err, new(*dap.ResponseError)) {
+		t.Errorf("got err=%v for a pause of an unknown thread, want *dap.ResponseError", err)
+	}
+	pc.call(dap.NewPauseRequest(dap.PauseArguments{ThreadId: 1}))
+	pc.expectStopped(dap.StoppedEventBodyReasonPause, 1)
+	if _, err := pc.c.Call(dap.NewPauseRequest(dap.PauseArguments{ThreadId: 1})); !errors.As(err, new(*dap.ResponseError)) {
+		t.Errorf("got err=%v for a pause of a stopped thread, want *dap.ResponseError", err)
+	}
+	frames := pc.call(dap.NewStackTraceRequest(dap.StackTraceArguments{ThreadId: 1})).(*dap.StackTraceResponse).Body.StackFrames
+	if len(frames) != 1 || frames[0].Line != 3 && frames[0].Line != 4 {
+		t.Errorf("got frames %#v, want main.main in the loop", frames)
+	}
+
+	pc.call(dap.NewContinueRequest(dap.ContinueArguments{ThreadId: 1}))
+	for _, req := range []dap.RequestMessage{
+		dap.NewNextRequest(dap.NextArguments{ThreadId: 1}),
+		dap.NewStackTraceRequest(dap.StackTraceArguments{ThreadId: 1}),
+	} {
+		if _, err := pc.c.Call(req); !errors.As(err, new(*dap.ResponseError)) {
+			t.Errorf("got err=%v for %T while running, want *dap.ResponseError", err, req)
+		}
+	}
+	pc.call(dap.NewTerminateRequest(nil))
+	pc.expect(dap.NewTerminatedEvent(dap.TerminatedEventBody{}))
+	if _, err := pc.c.Call(dap.NewPauseRequest(dap.PauseArguments{ThreadId: 1})); !errors.As(err, new(*dap.ResponseError)) {
+		t.Errorf("got err=%v for a pause after terminating, want *dap.ResponseError", err)
+	}
+}
+
+func TestProgramTerminateBeforeConfigurationDone(t *testing.T) {
+	for _, stopOnEntry := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stopOnEntry=%v", stopOnEntry), func(t *testing.T) {
+			pc, sc := initializeProgram(t, "testdata/program.json")
+			pc.call(dap.NewLaunchRequest(json.RawMessage(fmt.Sprintf(`{"stopOnEntry":%v}`, stopOnEntry))))
+			pc.call(dap.NewTerminateRequest(nil))
+			pc.expect(dap.NewTerminatedEvent(dap.TerminatedEventBody{}))
+			pc.call(dap.NewConfigurationDoneRequest(nil))
+			for _, thread := range sc.Threads {
+				pc.expect(dap.NewThreadEvent(dap.ThreadEventBody{Reason: dap.ThreadEventBodyReasonStarted, ThreadId: thread.Id}))
+			}
+
+			// The program does not start, and the session goes on.
+			resp := pc.call(dap.NewThreadsRequest()).(*dap.ThreadsResponse)
+			if len(resp.Body.Threads) != 0 {
+				t.Errorf("got threads %#v, want none", resp.Body.Threads)
+			}
+			if _, err := pc.c.Call(dap.NewContinueRequest(dap.ContinueArguments{ThreadId: 1})); !errors.As(err, new(*dap.ResponseError)) {
+				t.Errorf("got err=%v for continue, want *dap.ResponseError", err)
+			}
+		})
+	}
+}
+
+func TestProcessStartAfterTerminate(t *testing.T) {
+	sc, err := loadScenario("testdata/program.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stopOnEntry := range []bool{false, true} {
+		p := newProcess(sc.Program)
+		p.terminate()
+		if events := p.start(stopOnEntry); events != nil || p.running {
+			t.Errorf("start(%v) after terminate: got events %v, running %v, want none and not running", stopOnEntry, events, p.running)
+		}
+	}
+}
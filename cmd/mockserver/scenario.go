@@ -0,0 +1,329 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file defines scenarios, which describe the program that the fake
+// debugger pretends to debug. A scenario is loaded from a JSON file given
+// with the -scenario flag, whose properties use the JSON representation of
+// the corresponding DAP types:
+//
+//	{
+//	  "threads": [{"id": 1, "name": "main"}],
+//	  "output": [{"category": "stdout", "output": "starting\n"}],
+//	  "stackFrames": {"1": [{"id": 1000, "name": "main.main", "line": 5, "column": 1}]},
+//	  "scopes": {"1000": [{"name": "Locals", "variablesReference": 1}]},
+//	  "variables": {"1": [{"name": "i", "value": "1", "variablesReference": 0}]},
+//	  "sources": {"7": {"content": "package main\n"}},
+//	  "modules": [{"id": 1, "name": "main"}],
+//	  "stops": [
+//	    {"reason": "breakpoint", "threadId": 1, "hitBreakpointIds": [1]},
+//	    {"reason": "step", "threadId": 1,
+//	     "stackFrames": {"1": [{"id": 1000, "name": "main.main", "line": 6, "column": 1}]},
+//	     "variables": {"1": [{"name": "i", "value": "2", "variablesReference": 0}]}}
+//	  ],
+//	  "exitCode": 0
+//	}
+//
+// Stack frames are keyed by thread id, scopes by frame id, variables by
+// variables reference and sources by source reference. Each stop is sent
+// as a stopped event, preceded by its output events, when the program is
+// started or resumed by a continue, next, stepIn or stepOut request. While
+// stopped, the stack frames, scopes and variables of the stop take
+// precedence over those of the scenario. Once the stops are exhausted, the
+// program exits with the exit code of the scenario.
+//
+// Instead of stops, threads, stack frames, scopes and variables, a
+// scenario may describe a program that the fake debugger simulates; see
+// program.go.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/google/go-dap"
+)
+
+// scenario describes the program that the fake debugger debugs. It is not
+// modified once loaded, so it can be shared by concurrent sessions.
+type scenario struct {
+	Threads []dap.Thread `json:"threads"`
+	// Output is sent as output events once the configuration is done.
+	Output []dap.OutputEventBody `json:"output"`
+	// Stops lists the stops of the program in order. If it is nil, the
+	// program stops once for every breakpoint that is set, which is the
+	// behavior of the default scenario.
+	Stops    []scenarioStop                 `json:"stops"`
+	ExitCode int                            `json:"exitCode"`
+	Sources  map[int]dap.SourceResponseBody `json:"sources"`
+	Modules  []dap.Module                   `json:"modules"`
+	// Program is the program that the fake debugger simulates, if any.
+	Program *program `json:"program"`
+	scenarioState
+}
+
+// scenarioState is the state of the program while it is stopped.
+type scenarioState struct {
+	StackFrames map[int][]dap.StackFrame `json:"stackFrames"`
+	Scopes      map[int][]dap.Scope      `json:"scopes"`
+	Variables   map[int][]dap.Variable   `json:"variables"`
+}
+
+// scenarioStop is a stop of the program.
+type scenarioStop struct {
+	dap.StoppedEventBody
+	// Output is sent as output events before the stopped event.
+	Output []dap.OutputEventBody `json:"output"`
+	scenarioState
+}
+
+// defaultScenario is the scenario of the server when no scenario file is
+// given.
+var defaultScenario = &scenario{
+	Threads: []dap.Thread{{Id: 1, Name: "main"}},
+	Modules: []dap.Module{{Id: dap.IntOrStringFromInt(1), Name: "hello", Path: "/Users/foo/go/src/hello/hello"}},
+	scenarioState: scenarioState{
+		StackFrames: map[int][]dap.StackFrame{
+			1: {{
+				Id:     1000,
+				Source: &dap.Source{Name: "hello.go", Path: "/Users/foo/go/src/hello/hello.go", SourceReference: 0},
+				Line:   5,
+				Column: 0,
+				Name:   "main.main",
+			}},
+		},
+		Scopes: map[int][]dap.Scope{
+			1000: {
+				{Name: "Local", VariablesReference: 1000, Expensive: false},
+				{Name: "Global", VariablesReference: 1001, Expensive: true},
+			},
+		},
+		Variables: map[int][]dap.Variable{
+			1000: {{Name: "i", Value: "18434528", EvaluateName: "i", VariablesReference: 0}},
+		},
+	},
+}
+
+// loadScenario reads a scenario from the JSON file at path. Unknown
+// properties are reported as errors to catch misspellings.
+func loadScenario(path string) (*scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	sc := &scenario{}
+	if err := d.Decode(sc); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	if sc.Program != nil {
+		if err := sc.checkProgram(); err != nil {
+			return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+		}
+		for _, t := range sc.Program.Threads {
+			sc.Threads = append(sc.Threads, t.Thread)
+		}
+	}
+	if sc.Threads == nil {
+		sc.Threads = []dap.Thread{}
+	}
+	if sc.Modules == nil {
+		sc.Modules = []dap.Module{}
+	}
+	for i, stop := range sc.Stops {
+		if stop.Reason == "" {
+			return nil, fmt.Errorf("invalid scenario %s: stop %d has no reason", path, i)
+		}
+	}
+	return sc, nil
+}
+
+// checkProgram reports whether the program of sc is valid, and whether
+// the properties that it replaces are unset.
+func (sc *scenario) checkProgram() error {
+	replaced := []struct {
+		name string
+		set  bool
+	}{
+		{"threads", sc.Threads != nil},
+		{"stops", sc.Stops != nil},
+		{"stackFrames", sc.StackFrames != nil},
+		{"scopes", sc.Scopes != nil},
+		{"variables", sc.Variables != nil},
+	}
+	for _, r := range replaced {
+		if r.set {
+			return fmt.Errorf("%s cannot be set along with program", r.name)
+		}
+	}
+	return sc.Program.check()
+}
+
+// stackFrames returns the stack frames of thread threadId while the
+// program is stopped at stop, which may be nil. Like scopes and
+// variables, it returns an empty slice rather than nil, so that the
+// response has an empty array as the specification requires.
+func (sc *scenario) stackFrames(stop *scenarioStop, threadId int) []dap.StackFrame {
+	if stop != nil {
+		if frames, ok := stop.StackFrames[threadId]; ok {
+			return frames
+		}
+	}
+	if frames, ok := sc.StackFrames[threadId]; ok {
+		return frames
+	}
+	return []dap.StackFrame{}
+}
+
+// frameThread returns the thread whose stack frames hold the frame with
+// frameId while the program is stopped at stop, which may be nil.
+func (sc *scenario) frameThread(stop *scenarioStop, frameId int) (int, bool) {
+	for _, thread := range sc.Threads {
+		for _, f := range sc.stackFrames(stop, thread.Id) {
+			if f.Id == frameId {
+				return thread.Id, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// hasThread reports whether the scenario has a thread with id.
+func (sc *scenario) hasThread(id int) bool {
+	for _, thread := range sc.Threads {
+		if thread.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// scopes returns the scopes of frame frameId while the program is stopped
+// at stop, which may be nil.
+func (sc *scenario) scopes(stop *scenarioStop, frameId int) []dap.Scope {
+	if stop != nil {
+		if scopes, ok := stop.Scopes[frameId]; ok {
+			return scopes
+		}
+	}
+	if scopes, ok := sc.Scopes[frameId]; ok {
+		return scopes
+	}
+	return []dap.Scope{}
+}
+
+// variables returns the variables of reference variablesReference while
+// the program is stopped at stop, which may be nil.
+func (sc *scenario) variables(stop *scenarioStop, variablesReference int) []dap.Variable {
+	if stop != nil {
+		if variables, ok := stop.Variables[variablesReference]; ok {
+			return variables
+		}
+	}
+	if variables, ok := sc.Variables[variablesReference]; ok {
+		return variables
+	}
+	return []dap.Variable{}
+}
+
+// findVariable returns the first variable named name while the program is
+// stopped at stop, which may be nil, looking through the variables of
+// each reference in increasing order.
+func (sc *scenario) findVariable(stop *scenarioStop, name string) (dap.Variable, bool) {
+	for _, ref := range sc.variablesReferences(stop) {
+		for _, v := range sc.variables(stop, ref) {
+			if v.Name == name {
+				return v, true
+			}
+		}
+	}
+	return dap.Variable{}, false
+}
+
+// variableNames returns the sorted names of the variables while the
+// program is stopped at stop, which may be nil.
+func (sc *scenario) variableNames(stop *scenarioStop) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, ref := range sc.variablesReferences(stop) {
+		for _, v := range sc.variables(stop, ref) {
+			if !seen[v.Name] {
+				seen[v.Name] = true
+				names = append(names, v.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// variablesReferences returns the sorted references of the variables
+// while the program is stopped at stop, which may be nil.
+func (sc *scenario) variablesReferences(stop *scenarioStop) []int {
+	var refs []int
+	for ref := range sc.Variables {
+		refs = append(refs, ref)
+	}
+	if stop != nil {
+		for ref := range stop.Variables {
+			if _, ok := sc.Variables[ref]; !ok {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	sort.Ints(refs)
+	return refs
+}
+
+// loadedSources returns the sources of the stack frames of the scenario
+// and of its stops, or the source of its program, without duplicates.
+func (sc *scenario) loadedSources() []dap.Source {
+	sources := []dap.Source{}
+	type sourceKey struct {
+		name, path string
+		ref        int
+	}
+	seen := make(map[sourceKey]bool)
+	add := func(frames map[int][]dap.StackFrame) {
+		var threads []int
+		for id := range frames {
+			threads = append(threads, id)
+		}
+		sort.Ints(threads)
+		for _, id := range threads {
+			for _, f := range frames[id] {
+				if f.Source == nil {
+					continue
+				}
+				key := sourceKey{f.Source.Name, f.Source.Path, f.Source.SourceReference}
+				if !seen[key] {
+					seen[key] = true
+					sources = append(sources, *f.Source)
+				}
+			}
+		}
+	}
+	if sc.Program != nil {
+		sources = append(sources, sc.Program.Source)
+	}
+	add(sc.StackFrames)
+	for _, stop := range sc.Stops {
+		add(stop.StackFrames)
+	}
+	return sources
+}
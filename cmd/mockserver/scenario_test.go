@@ -0,0 +1,169 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-dap"
+)
+
+func TestScenario(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	sc, err := loadScenario("testdata/scenario.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConn, clientConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		handleConnection(serverConn, sc)
+		close(done)
+	}()
+	c := dap.NewClient(clientConn)
+	defer func() {
+		c.Close()
+		<-done
+	}()
+
+	call := func(req dap.RequestMessage) dap.ResponseMessage {
+		t.Helper()
+		resp, err := c.Call(req)
+		if err != nil {
+			t.Fatalf("%T: %v", req, err)
+		}
+		return resp
+	}
+	event := func() dap.EventMessage {
+		t.Helper()
+		e, ok := <-c.Events()
+		if !ok {
+			t.Fatal("connection closed, want event")
+		}
+		return e
+	}
+	expectOutput := func(want string) {
+		t.Helper()
+		if e, ok := event().(*dap.OutputEvent); !ok || e.Body.Output != want {
+			t.Errorf("got %#v, want output %q", e, want)
+		}
+	}
+	expectStopped := func(reason string) {
+		t.Helper()
+		if e, ok := event().(*dap.StoppedEvent); !ok || e.Body.Reason != reason || e.Body.ThreadId != 1 {
+			t.Errorf("got %#v, want stopped event with reason %q", e, reason)
+		}
+	}
+	expectFrames := func(levels int, wantLines ...int) {
+		t.Helper()
+		resp := call(dap.NewStackTraceRequest(dap.StackTraceArguments{ThreadId: 1, Levels: levels})).(*dap.StackTraceResponse)
+		var lines []int
+		for _, f := range resp.Body.StackFrames {
+			lines = append(lines, f.Line)
+		}
+		if !reflect.DeepEqual(lines, wantLines) || resp.Body.TotalFrames != 2 {
+			t.Errorf("got lines %v of %d frames, want %v of 2", lines, resp.Body.TotalFrames, wantLines)
+		}
+	}
+	expectVariable := func(want string) {
+		t.Helper()
+		resp := call(dap.NewVariablesRequest(dap.VariablesArguments{VariablesReference: 1})).(*dap.VariablesResponse)
+		if len(resp.Body.Variables) != 1 || resp.Body.Variables[0].Value != want {
+			t.Errorf("got %#v, want i = %s", resp.Body.Variables, want)
+		}
+	}
+
+	call(dap.NewInitializeRequest(dap.InitializeRequestArguments{AdapterID: "go"}))
+	if _, ok := event().(*dap.InitializedEvent); !ok {
+		t.Error("want initialized event")
+	}
+	call(dap.NewLaunchRequest(nil))
+	call(dap.NewConfigurationDoneRequest(nil))
+	for _, id := range []int{1, 2} {
+		if e, ok := event().(*dap.ThreadEvent); !ok || e.Body.ThreadId != id {
+			t.Errorf("got %#v, want thread event for thread %d", e, id)
+		}
+	}
+	expectOutput("starting\n")
+	expectOutput("i = 1\n")
+	expectStopped(dap.StoppedEventBodyReasonBreakpoint)
+
+	threads := call(dap.NewThreadsRequest()).(*dap.ThreadsResponse)
+	if !reflect.DeepEqual(threads.Body.Threads, sc.Threads) {
+		t.Errorf("got threads %#v, want %#v", threads.Body.Threads, sc.Threads)
+	}
+	expectFrames(1, 12)
+	scopes := call(dap.NewScopesRequest(dap.ScopesArguments{FrameId: 1000})).(*dap.ScopesResponse)
+	if len(scopes.Body.Scopes) != 1 || scopes.Body.Scopes[0].VariablesReference != 1 {
+		t.Errorf("got scopes %#v, want Locals", scopes.Body.Scopes)
+	}
+	expectVariable("1")
+
+	source := call(dap.NewSourceRequest(dap.SourceArguments{Source: &dap.Source{SourceReference: 7}})).(*dap.SourceResponse)
+	if source.Body.Content != "package main\n" {
+		t.Errorf("got source %#v", source.Body)
+	}
+	if _, err := c.Call(dap.NewSourceRequest(dap.SourceArguments{SourceReference: 8})); !errors.As(err, new(*dap.ResponseError)) {
+		t.Errorf("got err=%v for an unknown source, want *dap.ResponseError", err)
+	}
+
+	call(dap.NewNextRequest(dap.NextArguments{ThreadId: 1}))
+	expectStopped(dap.StoppedEventBodyReasonStep)
+	expectFrames(0, 13, 5)
+	expectVariable("2")
+
+	call(dap.NewContinueRequest(dap.ContinueArguments{ThreadId: 1}))
+	if e, ok := event().(*dap.ExitedEvent); !ok || e.Body.ExitCode != 3 {
+		t.Errorf("got %#v, want exited event with exit code 3", e)
+	}
+	if _, ok := event().(*dap.TerminatedEvent); !ok {
+		t.Error("want terminated event")
+	}
+	call(dap.NewDisconnectRequest(nil))
+}
+
+func TestLoadScenarioErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{`{"threads": [{"id": 1, "name": "main"}], "stop": []}`, `unknown field "stop"`},
+		{`{"stops": [{"threadId": 1}]}`, "stop 0 has no reason"},
+		{`{"variables": {"x": []}}`, "invalid scenario"},
+		{`{"program": {"threads": [{"id": 1, "function": "f"}], "functions": [{"name": "f", "statements": [{"line": 1}]}]}, "stops": []}`, "stops cannot be set along with program"},
+		{`{"program": {"functions": [{"name": "f", "statements": [{"line": 1}]}]}}`, "program has no threads"},
+		{`{"program": {"threads": [{"id": 1, "function": "g"}], "functions": [{"name": "f", "statements": [{"line": 1}]}]}}`, `thread 1 runs unknown function "g"`},
+		{`{"program": {"threads": [{"id": 1, "function": "f"}], "functions": [{"name": "f", "statements": [{"line": 1, "call": "g"}]}]}}`, `function "f" calls unknown function "g"`},
+		{`{"program": {"threads": [{"id": 1, "function": "f"}], "functions": [{"name": "f", "statements": [{"line": 1, "goto": 2}]}]}}`, `function "f" jumps to line 2`},
+		{`{"program": {"threads": [{"id": 1, "function": "f"}], "functions": [{"name": "f", "statements": [{"line": 1, "call": "g"}]}, {"name": "g", "statements": [{"line": 2, "call": "f"}]}]}}`, `function "g" calls "f" recursively`},
+	}
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "scenario.json")
+		if err := os.WriteFile(path, []byte(test.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadScenario(path); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("loadScenario(%s): got err=%v, want %q", test.data, err, test.want)
+		}
+	}
+}
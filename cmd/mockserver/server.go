@@ -14,278 +14,394 @@
 
 // This file defines helpers and request handlers for a dummy server
 // that accepts DAP requests and responds with dummy or error responses.
-// Fake-supported requests:
-// - initialize
-// - launch
-// - setBreakpoints
-// - setExceptionBreakpoints
-// - configurationDone
-// - threads
-// - stackTrace
-// - scopes
-// - variables
-// - continue
-// - disconnect
-// All other requests result in ErrorResponse's.
+// Every request of the specification is fake-supported: the server
+// implements dap.Handler, whose methods are generated from the
+// specification, so a request type added to it cannot go unhandled.
+// The threads, stack frames, scopes, variables, sources, modules,
+// stops and output of the program come from a scenario; see
+// scenario.go. A scenario may instead describe a program that the
+// server simulates, stepping through it as a real debugger would;
+// see program.go. Requests that cannot be decoded or that refer to
+// something the scenario lacks result in ErrorResponse's.
 //
 // The server uses the following goroutines:
-// - "main" goroutine accepts client connections one by one and
-//   handles them serially by reading and decoding incoming requests
-//   and dispatching each one to a new goroutine for further
-//   processing.
+// - "main" goroutine accepts client connections one by one, or
+//   serves the single client on the standard input and output.
+// - per-connection goroutines run a dap.Session for each connection,
+//   which reads and decodes incoming requests and dispatches each one
+//   to a new goroutine for further processing.
 // - per-request goroutines process each request as if
-//   letting fake debugger take over. They send events and responses
-//   via the sender goroutine.
-// - sender goroutine listens for messages to send and
-//   writes them to the client connection.
+//   letting fake debugger take over. They send events
+//   directly to the client connection, and the session sends
+//   their responses, which is safe to do concurrently as every
+//   message is written as a whole.
+// - per-connection goroutines run the simulated program, if any,
+//   while it is not stopped, and send the events that it causes.
 //
 
 package main
 
 import (
-	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/go-dap"
 )
 
-// server starts a server that listens on a specified port
-// and blocks indefinitely. This server can accept multiple
-// client connections at the same time.
-func server(port string) error {
-	listener, err := net.Listen("tcp", ":"+port)
-	if err != nil {
-		return err
-	}
+// serve accepts client connections from listener and blocks
+// until the listener is closed. This server can accept multiple
+// client connections at the same time, each of which debugs
+// its own run of the program described by sc.
+func serve(listener net.Listener, sc *scenario) error {
 	defer listener.Close()
 	log.Println("Started server at", listener.Addr())
 
 	for {
 		conn, err := listener.Accept()
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
 			log.Println("Connection failed:", err)
 			continue
 		}
 		log.Println("Accepted connection from", conn.RemoteAddr())
 		// Handle multiple client connections concurrently
-		go handleConnection(conn)
+		go func() {
+			handleConnection(conn, sc)
+			log.Println("Closed connection from", conn.RemoteAddr())
+		}()
 	}
 }
 
-// handleConnection handles a connection from a single client.
-// It reads and decodes the incoming data and dispatches it
-// to per-request processing goroutines. It also launches the
-// sender goroutine to send resulting messages over the connection
-// back to the client.
-func handleConnection(conn net.Conn) {
-	debugSession := fakeDebugSession{
-		rw:        bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)),
-		sendQueue: make(chan dap.Message),
-		stopDebug: make(chan struct{}),
+// handleConnection handles a connection from a single client,
+// such as a network connection or the standard input and output.
+// It serves a session on the connection, which dispatches the
+// incoming requests to per-request processing goroutines, and
+// returns once the connection fails or the client closes it.
+func handleConnection(conn io.ReadWriteCloser, sc *scenario) {
+	debugSession := &fakeDebugSession{
+		s:        dap.NewSession(logConn{conn}),
+		scenario: sc,
 	}
-	go debugSession.sendFromQueue()
-
-	for {
-		err := debugSession.handleRequest()
-		// TODO(polina): check for connection vs decoding error?
-		if err != nil {
-			if err == io.EOF {
-				log.Println("No more data to read:", err)
-				break
-			}
-			// There maybe more messages to process, but
-			// we will start with the strict behavior of only accepting
-			// expected inputs.
-			log.Fatal("Server error: ", err)
-		}
+	if sc.Program != nil {
+		debugSession.process = newProcess(sc.Program)
 	}
+	// Skip over malformed messages to the next one rather than
+	// giving up on the connection.
+	debugSession.s.SetFramingErrorHandler(func(err error, discarded int64) {
+		log.Printf("Skipped %d bytes after framing error: %v\n", discarded, err)
+	})
 
-	log.Println("Closing connection from", conn.RemoteAddr())
-	close(debugSession.stopDebug)
-	debugSession.sendWg.Wait()
-	close(debugSession.sendQueue)
+	if err := debugSession.s.Serve(debugSession); err != nil {
+		log.Println("Connection error:", err)
+	} else {
+		log.Println("No more data to read")
+	}
+	debugSession.stopRunning()
 	conn.Close()
 }
 
-func (ds *fakeDebugSession) handleRequest() error {
-	log.Println("Reading request...")
-	request, err := dap.ReadProtocolMessage(ds.rw.Reader)
-	if err != nil {
-		return err
+// logConn logs the data read from and written to a connection.
+type logConn struct {
+	io.ReadWriteCloser
+}
+
+func (c logConn) Read(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(p)
+	if n > 0 {
+		log.Printf("Received\n\t%s\n", p[:n])
 	}
-	log.Printf("Received request\n\t%#v\n", request)
-	ds.sendWg.Add(1)
-	go func() {
-		ds.dispatchRequest(request)
-		ds.sendWg.Done()
-	}()
-	return nil
+	return n, err
 }
 
-// dispatchRequest launches a new goroutine to process each request
-// and send back events and responses.
-func (ds *fakeDebugSession) dispatchRequest(request dap.Message) {
-	switch request := request.(type) {
-	case *dap.InitializeRequest:
-		ds.onInitializeRequest(request)
-	case *dap.LaunchRequest:
-		ds.onLaunchRequest(request)
-	case *dap.AttachRequest:
-		ds.onAttachRequest(request)
-	case *dap.DisconnectRequest:
-		ds.onDisconnectRequest(request)
-	case *dap.TerminateRequest:
-		ds.onTerminateRequest(request)
-	case *dap.RestartRequest:
-		ds.onRestartRequest(request)
-	case *dap.SetBreakpointsRequest:
-		ds.onSetBreakpointsRequest(request)
-	case *dap.SetFunctionBreakpointsRequest:
-		ds.onSetFunctionBreakpointsRequest(request)
-	case *dap.SetExceptionBreakpointsRequest:
-		ds.onSetExceptionBreakpointsRequest(request)
-	case *dap.ConfigurationDoneRequest:
-		ds.onConfigurationDoneRequest(request)
-	case *dap.ContinueRequest:
-		ds.onContinueRequest(request)
-	case *dap.NextRequest:
-		ds.onNextRequest(request)
-	case *dap.StepInRequest:
-		ds.onStepInRequest(request)
-	case *dap.StepOutRequest:
-		ds.onStepOutRequest(request)
-	case *dap.StepBackRequest:
-		ds.onStepBackRequest(request)
-	case *dap.ReverseContinueRequest:
-		ds.onReverseContinueRequest(request)
-	case *dap.RestartFrameRequest:
-		ds.onRestartFrameRequest(request)
-	case *dap.GotoRequest:
-		ds.onGotoRequest(request)
-	case *dap.PauseRequest:
-		ds.onPauseRequest(request)
-	case *dap.StackTraceRequest:
-		ds.onStackTraceRequest(request)
-	case *dap.ScopesRequest:
-		ds.onScopesRequest(request)
-	case *dap.VariablesRequest:
-		ds.onVariablesRequest(request)
-	case *dap.SetVariableRequest:
-		ds.onSetVariableRequest(request)
-	case *dap.SetExpressionRequest:
-		ds.onSetExpressionRequest(request)
-	case *dap.SourceRequest:
-		ds.onSourceRequest(request)
-	case *dap.ThreadsRequest:
-		ds.onThreadsRequest(request)
-	case *dap.TerminateThreadsRequest:
-		ds.onTerminateThreadsRequest(request)
-	case *dap.EvaluateRequest:
-		ds.onEvaluateRequest(request)
-	case *dap.StepInTargetsRequest:
-		ds.onStepInTargetsRequest(request)
-	case *dap.GotoTargetsRequest:
-		ds.onGotoTargetsRequest(request)
-	case *dap.CompletionsRequest:
-		ds.onCompletionsRequest(request)
-	case *dap.ExceptionInfoRequest:
-		ds.onExceptionInfoRequest(request)
-	case *dap.LoadedSourcesRequest:
-		ds.onLoadedSourcesRequest(request)
-	case *dap.DataBreakpointInfoRequest:
-		ds.onDataBreakpointInfoRequest(request)
-	case *dap.SetDataBreakpointsRequest:
-		ds.onSetDataBreakpointsRequest(request)
-	case *dap.ReadMemoryRequest:
-		ds.onReadMemoryRequest(request)
-	case *dap.DisassembleRequest:
-		ds.onDisassembleRequest(request)
-	case *dap.CancelRequest:
-		ds.onCancelRequest(request)
-	case *dap.BreakpointLocationsRequest:
-		ds.onBreakpointLocationsRequest(request)
-	default:
-		log.Fatalf("Unable to process %#v", request)
-	}
-}
-
-// send lets the sender goroutine know via a channel that there is
-// a message to be sent to client. This is called by per-request
-// goroutines to send events and responses for each request and
-// to notify of events triggered by the fake debugger.
-func (ds *fakeDebugSession) send(message dap.Message) {
-	ds.sendQueue <- message
+func (c logConn) Write(p []byte) (int, error) {
+	log.Printf("Sending\n\t%s\n", p)
+	return c.ReadWriteCloser.Write(p)
 }
 
-// sendFromQueue is to be run in a separate goroutine to listen on a
-// channel for messages to send back to the client. It will
-// return once the channel is closed.
-func (ds *fakeDebugSession) sendFromQueue() {
-	for message := range ds.sendQueue {
-		dap.WriteProtocolMessage(ds.rw.Writer, message)
-		log.Printf("Message sent\n\t%#v\n", message)
-		ds.rw.Flush()
+// send writes an event to the client. This is called by per-request
+// goroutines to send events for each request and to notify of events
+// triggered by the fake debugger.
+func (ds *fakeDebugSession) send(message dap.Message) {
+	if err := ds.s.Send(message); err != nil {
+		log.Println("Failed to send message:", err)
 	}
 }
 
+// maxMemoryCount and maxInstructionCount are the most bytes of memory
+// and instructions that the fake debugger reads or disassembles at once,
+// so that a client cannot make it allocate without bound.
+const (
+	maxMemoryCount      = 1 << 20
+	maxInstructionCount = 1 << 16
+)
+
+// mockError returns the error with which a handler fails, which the
+// session sends to the client as an ErrorResponse.
+func mockError(format string, args ...any) error {
+	return &dap.Error{Id: 12345, Format: fmt.Sprintf(format, args...)}
+}
+
 // -----------------------------------------------------------------------
 // Very Fake Debugger
 //
 
-// The debugging session will keep track of how many breakpoints
-// have been set. Once start-up is done (i.e. configurationDone
-// request is processed), it will "stop" at each breakpoint one by
-// one, and once there are no more, it will trigger a terminated event.
+// The debugging session replays its scenario. Once start-up is done
+// (i.e. configurationDone request is processed), it will "stop" at
+// each stop of the scenario one by one, and once there are no more,
+// it will trigger exited and terminated events. If the scenario has
+// no stops, the debugging session will instead keep track of how many
+// breakpoints have been set and "stop" at each of them. If the scenario
+// has a program, the debugging session will rather run it and stop
+// where it should.
 type fakeDebugSession struct {
-	// rw is used to read requests and write events/responses
-	rw *bufio.ReadWriter
+	// s reads requests from the client connection, dispatches them
+	// to the handlers below and sends their responses. Events are
+	// sent with it too, each stamped with the next sequence number.
+	s *dap.Session
 
-	// sendQueue is used to capture messages from multiple request
-	// processing goroutines while writing them to the client connection
-	// from a single goroutine via sendFromQueue. We must keep track of
-	// the multiple channel senders with a wait group to make sure we do
-	// not close this channel prematurely. Closing this channel will signal
-	// the sendFromQueue goroutine that it can exit.
-	sendQueue chan dap.Message
-	sendWg    sync.WaitGroup
-
-	// stopDebug is used to notify long-running handlers to stop processing.
-	stopDebug chan struct{}
+	// scenario describes the program being debugged.
+	scenario *scenario
 
+	// mu guards the fields below.
+	mu sync.Mutex
 	// bpSet is a counter of the remaining breakpoints that the debug
-	// session is yet to stop at before the program terminates.
-	bpSet    int
-	bpSetMux sync.Mutex
+	// session is yet to stop at before the program terminates, if the
+	// scenario has no stops.
+	bpSet int
+	// nextStop is the index of the next stop of the scenario.
+	nextStop int
+	// stop is the current stop of the scenario, if any.
+	stop *scenarioStop
+	// process is the run of the program of the scenario, if any.
+	process *process
+	// runs counts the goroutines started to run the process, which
+	// each stop once another one starts.
+	runs int
+	// stopOnEntry is whether the program stops before it runs.
+	stopOnEntry bool
+	// terminated is whether the terminated event was sent, which is
+	// done once per run of the program.
+	terminated bool
 }
 
+// fakeDebugSession handles every request, rather than embedding
+// dap.UnimplementedHandler.
+var _ dap.Handler = (*fakeDebugSession)(nil)
+
 // doContinue allows fake program execution to continue when the program
 // is started or unpaused. It simulates events from the debug session
-// by "stopping" on a breakpoint or terminating if there are no more
-// breakpoints. Safe to use concurrently.
+// by "stopping" at the next stop of the scenario, or on a breakpoint
+// if the scenario has no stops, or terminating if there are no more.
+// Safe to use concurrently.
 func (ds *fakeDebugSession) doContinue() {
-	var e dap.Message
-	ds.bpSetMux.Lock()
-	if ds.bpSet == 0 {
+	var events []dap.Message
+	var delay time.Duration
+	ds.mu.Lock()
+	switch {
+	case ds.terminated:
+		// The program is already gone.
+	case ds.scenario.Stops == nil && ds.bpSet == 0:
 		// Pretend that the program is running.
 		// The delay will allow for all in-flight responses
 		// to be sent before termination.
-		time.Sleep(1000 * time.Millisecond)
-		e = &dap.TerminatedEvent{
-			Event: *newEvent("terminated"),
+		delay = 1000 * time.Millisecond
+		ds.terminated = true
+		events = append(events, dap.NewTerminatedEvent(dap.TerminatedEventBody{}))
+	case ds.scenario.Stops == nil:
+		events = append(events, dap.NewStoppedEvent(dap.StoppedEventBody{Reason: dap.StoppedEventBodyReasonBreakpoint, ThreadId: 1, AllThreadsStopped: true}))
+		ds.bpSet--
+	case ds.nextStop < len(ds.scenario.Stops):
+		ds.stop = &ds.scenario.Stops[ds.nextStop]
+		ds.nextStop++
+		for _, output := range ds.stop.Output {
+			events = append(events, dap.NewOutputEvent(output))
 		}
-	} else {
-		e = &dap.StoppedEvent{
-			Event: *newEvent("stopped"),
-			Body:  dap.StoppedEventBody{Reason: "breakpoint", ThreadId: 1, AllThreadsStopped: true},
+		events = append(events, dap.NewStoppedEvent(ds.stop.StoppedEventBody))
+	case ds.nextStop == len(ds.scenario.Stops):
+		ds.stop = nil
+		ds.nextStop++
+		ds.terminated = true
+		events = append(events,
+			dap.NewExitedEvent(dap.ExitedEventBody{ExitCode: ds.scenario.ExitCode}),
+			dap.NewTerminatedEvent(dap.TerminatedEventBody{}))
+	}
+	ds.mu.Unlock()
+	time.Sleep(delay)
+	for _, e := range events {
+		ds.send(e)
+	}
+}
+
+// doReverse lets fake program execution run backwards to the previous
+// stop of the scenario, or stay at the first one. Without stops, it
+// simulates a step to where the program is already stopped.
+func (ds *fakeDebugSession) doReverse() {
+	ds.mu.Lock()
+	if ds.stop != nil && ds.nextStop > 1 {
+		ds.nextStop--
+		ds.stop = &ds.scenario.Stops[ds.nextStop-1]
+	}
+	body := dap.StoppedEventBody{Reason: dap.StoppedEventBodyReasonStep, ThreadId: 1, AllThreadsStopped: true}
+	if ds.stop != nil {
+		body = ds.stop.StoppedEventBody
+	}
+	ds.mu.Unlock()
+	ds.send(dap.NewStoppedEvent(body))
+}
+
+// doStop simulates the program stopping where it is for reason.
+func (ds *fakeDebugSession) doStop(reason string, threadId int) {
+	ds.send(dap.NewStoppedEvent(dap.StoppedEventBody{Reason: reason, ThreadId: threadId, AllThreadsStopped: true}))
+}
+
+// statementDuration is how long the simulated program takes to run a
+// statement, which leaves time to pause it.
+var statementDuration = time.Millisecond
+
+// doStart starts the simulated program, which stops on entry if the
+// launch request said so, unless it was terminated before. The requests
+// that follow the one handled with ctx see the program started, but the
+// events that this causes are only sent, and the program only runs, once
+// the response has been sent.
+func (ds *fakeDebugSession) doStart(ctx context.Context) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.process.exited {
+		return
+	}
+	events := ds.process.start(ds.stopOnEntry)
+	run := 0
+	if ds.process.running {
+		ds.runs++
+		run = ds.runs
+	}
+	ds.s.AfterResponse(ctx, func() {
+		for _, e := range events {
+			ds.send(e)
 		}
-		ds.bpSet--
+		if run != 0 {
+			go ds.runProcess(run)
+		}
+	})
+}
+
+// doResume lets the threads of the simulated program run, once the
+// response to the request handled with ctx has been sent. Only thread
+// threadId runs if singleThread is set, and it steps if step is not
+// stepNone.
+func (ds *fakeDebugSession) doResume(ctx context.Context, threadId int, singleThread bool, step stepKind) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	p := ds.process
+	switch {
+	case p.exited:
+		return mockError("The program has exited")
+	case p.running && step != stepNone:
+		return mockError("Thread %d is running", threadId)
+	}
+	if err := p.resume(threadId, singleThread, step); err != nil {
+		return err
+	}
+	ds.runs++
+	run := ds.runs
+	ds.s.AfterResponse(ctx, func() { go ds.runProcess(run) })
+	return nil
+}
+
+// runProcess runs the simulated program one statement at a time until
+// its threads stop, or until another run starts. Events are sent while
+// ds.mu is held, so that those of a run are sent before the events of a
+// request that stops it, such as pause.
+func (ds *fakeDebugSession) runProcess(run int) {
+	for {
+		ds.mu.Lock()
+		if ds.runs != run || !ds.process.running {
+			ds.mu.Unlock()
+			return
+		}
+		for _, e := range ds.process.tick(ds.scenario.ExitCode) {
+			ds.send(e)
+		}
+		ds.mu.Unlock()
+		time.Sleep(statementDuration)
 	}
-	ds.bpSetMux.Unlock()
-	ds.send(e)
+}
+
+// stopRunning stops the goroutine that runs the simulated program, if
+// any.
+func (ds *fakeDebugSession) stopRunning() {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.runs++
+}
+
+// stoppedThread returns the thread of the simulated program with id,
+// which must be stopped.
+func (ds *fakeDebugSession) stoppedThread(id int) (*threadState, error) {
+	t := ds.process.thread(id)
+	switch {
+	case t == nil:
+		return nil, mockError("No thread %d", id)
+	case ds.process.running:
+		return nil, mockError("Thread %d is running", id)
+	}
+	return t, nil
+}
+
+// frame returns the frame of the simulated program with id, or the top
+// frame of its first thread if id is zero.
+func (ds *fakeDebugSession) frame(id int) (*frameState, error) {
+	p := ds.process
+	if id == 0 && len(p.threads) > 0 {
+		return p.threads[0].top(), nil
+	}
+	if f := p.frame(id); f != nil {
+		return f, nil
+	}
+	return nil, mockError("No frame %d", id)
+}
+
+// currentStop returns the current stop of the scenario, or nil if the
+// program is not stopped at one.
+func (ds *fakeDebugSession) currentStop() *scenarioStop {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	return ds.stop
+}
+
+// requestCapabilities maps the commands of the requests that clients may
+// only send if the debug adapter says it supports them to the function
+// that sets the capability that says so. Each handler of such a request
+// registers its command with supports, next to its definition, so that
+// the response to the initialize request advertises the requests that
+// are handled.
+var requestCapabilities = map[string]func(c *dap.Capabilities){}
+
+// supports registers that fakeDebugSession supports the requests with
+// command, which set advertises in its capabilities.
+func supports(command string, set func(c *dap.Capabilities)) bool {
+	requestCapabilities[command] = set
+	return true
+}
+
+// capabilities returns the capabilities of the fake debugger.
+func capabilities() dap.Capabilities {
+	var c dap.Capabilities
+	for _, set := range requestCapabilities {
+		set(&c)
+	}
+	return c
 }
 
 // -----------------------------------------------------------------------
@@ -296,299 +412,657 @@ func (ds *fakeDebugSession) doContinue() {
 // A real debug adaptor would call the debugger methods here
 // and use their results to populate each response.
 
-func (ds *fakeDebugSession) onInitializeRequest(request *dap.InitializeRequest) {
-	response := &dap.InitializeResponse{}
-	response.Response = *newResponse(request.Seq, request.Command)
-	response.Body.SupportsConfigurationDoneRequest = true
-	response.Body.SupportsFunctionBreakpoints = false
-	response.Body.SupportsConditionalBreakpoints = false
-	response.Body.SupportsHitConditionalBreakpoints = false
-	response.Body.SupportsEvaluateForHovers = false
-	response.Body.ExceptionBreakpointFilters = []dap.ExceptionBreakpointsFilter{}
-	response.Body.SupportsStepBack = false
-	response.Body.SupportsSetVariable = false
-	response.Body.SupportsRestartFrame = false
-	response.Body.SupportsGotoTargetsRequest = false
-	response.Body.SupportsStepInTargetsRequest = false
-	response.Body.SupportsCompletionsRequest = false
-	response.Body.CompletionTriggerCharacters = []string{}
-	response.Body.SupportsModulesRequest = false
-	response.Body.AdditionalModuleColumns = []dap.ColumnDescriptor{}
-	response.Body.SupportedChecksumAlgorithms = []dap.ChecksumAlgorithm{}
-	response.Body.SupportsRestartRequest = false
-	response.Body.SupportsExceptionOptions = false
-	response.Body.SupportsValueFormattingOptions = false
-	response.Body.SupportsExceptionInfoRequest = false
-	response.Body.SupportTerminateDebuggee = false
-	response.Body.SupportsDelayedStackTraceLoading = false
-	response.Body.SupportsLoadedSourcesRequest = false
-	response.Body.SupportsLogPoints = false
-	response.Body.SupportsTerminateThreadsRequest = false
-	response.Body.SupportsSetExpression = false
-	response.Body.SupportsTerminateRequest = false
-	response.Body.SupportsDataBreakpoints = false
-	response.Body.SupportsReadMemoryRequest = false
-	response.Body.SupportsDisassembleRequest = false
-	response.Body.SupportsCancelRequest = false
-	response.Body.SupportsBreakpointLocationsRequest = false
+var _ = supports(dap.CommandCancel, func(c *dap.Capabilities) { c.SupportsCancelRequest = true })
+
+func (ds *fakeDebugSession) OnCancelRequest(ctx context.Context, request *dap.CancelRequest) (*dap.CancelResponse, error) {
+	// The session has already cancelled the request, whose handler
+	// stops early if it is still running, as OnVariablesRequest does.
+	return nil, nil
+}
+
+// The reverse requests are meant for the client, but a client that
+// sends them gets plausible responses as well.
+
+func (ds *fakeDebugSession) OnRunInTerminalRequest(ctx context.Context, request *dap.RunInTerminalRequest) (*dap.RunInTerminalResponse, error) {
+	return dap.NewRunInTerminalResponse(request, dap.RunInTerminalResponseBody{ProcessId: 4242}), nil
+}
+
+func (ds *fakeDebugSession) OnStartDebuggingRequest(ctx context.Context, request *dap.StartDebuggingRequest) (*dap.StartDebuggingResponse, error) {
+	return nil, nil
+}
+
+func (ds *fakeDebugSession) OnInitializeRequest(ctx context.Context, request *dap.InitializeRequest) (*dap.InitializeResponse, error) {
 	// This is a fake set up, so we can start "accepting" configuration
 	// requests for setting breakpoints, etc from the client at any time.
-	// Notify the client with an 'initialized' event. The client will end
-	// the configuration sequence with 'configurationDone' request.
-	e := &dap.InitializedEvent{Event: *newEvent("initialized")}
-	ds.send(e)
-	ds.send(response)
+	// Notify the client with an 'initialized' event once it has the
+	// response. The client will end the configuration sequence with
+	// 'configurationDone' request.
+	ds.s.AfterResponse(ctx, func() { ds.send(dap.NewInitializedEvent()) })
+	return dap.NewInitializeResponse(request, capabilities()), nil
+}
+
+var _ = supports(dap.CommandConfigurationDone, func(c *dap.Capabilities) { c.SupportsConfigurationDoneRequest = true })
+
+func (ds *fakeDebugSession) OnConfigurationDoneRequest(ctx context.Context, request *dap.ConfigurationDoneRequest) (*dap.ConfigurationDoneResponse, error) {
+	// This would be the place to check if the session was configured to
+	// stop on entry and if that is the case, to issue a
+	// stopped-on-breakpoint event. This being a mock implementation,
+	// we "let" the program continue after sending a successful response.
+	for _, thread := range ds.scenario.Threads {
+		ds.send(dap.NewThreadEvent(dap.ThreadEventBody{Reason: dap.ThreadEventBodyReasonStarted, ThreadId: thread.Id}))
+	}
+	ds.s.AfterResponse(ctx, func() {
+		for _, output := range ds.scenario.Output {
+			ds.send(dap.NewOutputEvent(output))
+		}
+	})
+	if ds.process != nil {
+		ds.doStart(ctx)
+		return dap.NewConfigurationDoneResponse(request), nil
+	}
+	ds.s.AfterResponse(ctx, ds.doContinue)
+	return dap.NewConfigurationDoneResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onLaunchRequest(request *dap.LaunchRequest) {
+func (ds *fakeDebugSession) OnLaunchRequest(ctx context.Context, request *dap.LaunchRequest) (*dap.LaunchResponse, error) {
 	// This is where a real debug adaptor would check the soundness of the
 	// arguments (e.g. program from launch.json) and then use them to launch the
 	// debugger and attach to the program.
-	response := &dap.LaunchResponse{}
-	response.Response = *newResponse(request.Seq, request.Command)
-	ds.send(response)
+	var args struct {
+		StopOnEntry bool `json:"stopOnEntry"`
+	}
+	if err := json.Unmarshal(request.Arguments, &args); err == nil {
+		ds.mu.Lock()
+		ds.stopOnEntry = args.StopOnEntry
+		ds.mu.Unlock()
+	}
+	return dap.NewLaunchResponse(request), nil
+}
+
+func (ds *fakeDebugSession) OnAttachRequest(ctx context.Context, request *dap.AttachRequest) (*dap.AttachResponse, error) {
+	// Attaching to the fake program is no different from launching it.
+	return dap.NewAttachResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onAttachRequest(request *dap.AttachRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "AttachRequest is not yet supported"))
+var _ = supports(dap.CommandRestart, func(c *dap.Capabilities) { c.SupportsRestartRequest = true })
+
+func (ds *fakeDebugSession) OnRestartRequest(ctx context.Context, request *dap.RestartRequest) (*dap.RestartResponse, error) {
+	// Run the program again from the start.
+	ds.mu.Lock()
+	ds.nextStop = 0
+	ds.stop = nil
+	ds.runs++
+	ds.terminated = false
+	if ds.process != nil {
+		ds.process.reset()
+	}
+	ds.mu.Unlock()
+	if ds.process != nil {
+		ds.doStart(ctx)
+		return dap.NewRestartResponse(request), nil
+	}
+	ds.s.AfterResponse(ctx, ds.doContinue)
+	return dap.NewRestartResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onDisconnectRequest(request *dap.DisconnectRequest) {
-	response := &dap.DisconnectResponse{}
-	response.Response = *newResponse(request.Seq, request.Command)
-	ds.send(response)
+func (ds *fakeDebugSession) OnDisconnectRequest(ctx context.Context, request *dap.DisconnectRequest) (*dap.DisconnectResponse, error) {
+	return dap.NewDisconnectResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onTerminateRequest(request *dap.TerminateRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "TerminateRequest is not yet supported"))
+var _ = supports(dap.CommandTerminate, func(c *dap.Capabilities) { c.SupportsTerminateRequest = true })
+
+func (ds *fakeDebugSession) OnTerminateRequest(ctx context.Context, request *dap.TerminateRequest) (*dap.TerminateResponse, error) {
+	ds.mu.Lock()
+	ds.nextStop = len(ds.scenario.Stops) + 1
+	ds.stop = nil
+	ds.bpSet = 0
+	ds.runs++
+	// The simulated program sends the terminated event itself once it
+	// exits.
+	terminated := ds.terminated || ds.process != nil && ds.process.exited
+	ds.terminated = true
+	if ds.process != nil {
+		ds.process.terminate()
+	}
+	ds.mu.Unlock()
+	if !terminated {
+		ds.s.AfterResponse(ctx, func() {
+			ds.send(dap.NewTerminatedEvent(dap.TerminatedEventBody{}))
+		})
+	}
+	return dap.NewTerminateResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onRestartRequest(request *dap.RestartRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "RestartRequest is not yet supported"))
+var _ = supports(dap.CommandBreakpointLocations, func(c *dap.Capabilities) { c.SupportsBreakpointLocationsRequest = true })
+
+func (ds *fakeDebugSession) OnBreakpointLocationsRequest(ctx context.Context, request *dap.BreakpointLocationsRequest) (*dap.BreakpointLocationsResponse, error) {
+	// Any line is a fine place for a breakpoint, except in the simulated
+	// program, where only the lines with a statement are.
+	locations := []dap.BreakpointLocation{}
+	if args := request.Arguments; args != nil && ds.process != nil {
+		if ds.process.prog.isSource(args.Source) {
+			end := args.EndLine
+			if end < args.Line {
+				end = args.Line
+			}
+			for _, line := range ds.process.prog.lines() {
+				if line >= args.Line && line <= end {
+					locations = append(locations, dap.BreakpointLocation{Line: line})
+				}
+			}
+		}
+	} else if request.Arguments != nil {
+		locations = append(locations, dap.BreakpointLocation{Line: request.Arguments.Line})
+	}
+	return dap.NewBreakpointLocationsResponse(request, dap.BreakpointLocationsResponseBody{Breakpoints: locations}), nil
 }
 
-func (ds *fakeDebugSession) onSetBreakpointsRequest(request *dap.SetBreakpointsRequest) {
-	response := &dap.SetBreakpointsResponse{}
-	response.Response = *newResponse(request.Seq, request.Command)
+func (ds *fakeDebugSession) OnSetBreakpointsRequest(ctx context.Context, request *dap.SetBreakpointsRequest) (*dap.SetBreakpointsResponse, error) {
+	if p := ds.process; p != nil {
+		lines := request.Arguments.Lines
+		if request.Arguments.Breakpoints != nil {
+			lines = make([]int, len(request.Arguments.Breakpoints))
+			for i, b := range request.Arguments.Breakpoints {
+				lines[i] = b.Line
+			}
+		}
+		var breakpoints []dap.Breakpoint
+		if p.prog.isSource(request.Arguments.Source) {
+			ds.mu.Lock()
+			breakpoints = p.setBreakpoints(lines)
+			ds.mu.Unlock()
+		} else {
+			breakpoints = make([]dap.Breakpoint, len(lines))
+			for i, line := range lines {
+				breakpoints[i] = dap.Breakpoint{Line: line, Message: "Unknown source"}
+			}
+		}
+		return dap.NewSetBreakpointsResponse(request, dap.SetBreakpointsResponseBody{Breakpoints: breakpoints}), nil
+	}
+	response := dap.NewSetBreakpointsResponse(request, dap.SetBreakpointsResponseBody{})
 	response.Body.Breakpoints = make([]dap.Breakpoint, len(request.Arguments.Breakpoints))
 	for i, b := range request.Arguments.Breakpoints {
 		response.Body.Breakpoints[i].Line = b.Line
 		response.Body.Breakpoints[i].Verified = true
-		ds.bpSetMux.Lock()
+		ds.mu.Lock()
 		ds.bpSet++
-		ds.bpSetMux.Unlock()
+		ds.mu.Unlock()
 	}
-	ds.send(response)
+	return response, nil
 }
 
-func (ds *fakeDebugSession) onSetFunctionBreakpointsRequest(request *dap.SetFunctionBreakpointsRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "SetFunctionBreakpointsRequest is not yet supported"))
+var _ = supports(dap.CommandSetFunctionBreakpoints, func(c *dap.Capabilities) { c.SupportsFunctionBreakpoints = true })
+
+func (ds *fakeDebugSession) OnSetFunctionBreakpointsRequest(ctx context.Context, request *dap.SetFunctionBreakpointsRequest) (*dap.SetFunctionBreakpointsResponse, error) {
+	return dap.NewSetFunctionBreakpointsResponse(request, dap.SetFunctionBreakpointsResponseBody{
+		Breakpoints: verifiedBreakpoints(len(request.Arguments.Breakpoints)),
+	}), nil
 }
 
-func (ds *fakeDebugSession) onSetExceptionBreakpointsRequest(request *dap.SetExceptionBreakpointsRequest) {
-	response := &dap.SetExceptionBreakpointsResponse{}
-	response.Response = *newResponse(request.Seq, request.Command)
-	ds.send(response)
+func (ds *fakeDebugSession) OnSetExceptionBreakpointsRequest(ctx context.Context, request *dap.SetExceptionBreakpointsRequest) (*dap.SetExceptionBreakpointsResponse, error) {
+	return dap.NewSetExceptionBreakpointsResponse(request, dap.SetExceptionBreakpointsResponseBody{}), nil
 }
 
-func (ds *fakeDebugSession) onConfigurationDoneRequest(request *dap.ConfigurationDoneRequest) {
-	// This would be the place to check if the session was configured to
-	// stop on entry and if that is the case, to issue a
-	// stopped-on-breakpoint event. This being a mock implementation,
-	// we "let" the program continue after sending a successful response.
-	e := &dap.ThreadEvent{Event: *newEvent("thread"), Body: dap.ThreadEventBody{Reason: "started", ThreadId: 1}}
-	ds.send(e)
-	response := &dap.ConfigurationDoneResponse{}
-	response.Response = *newResponse(request.Seq, request.Command)
-	ds.send(response)
-	ds.doContinue()
+var _ = supports(dap.CommandDataBreakpointInfo, func(c *dap.Capabilities) { c.SupportsDataBreakpoints = true })
+
+func (ds *fakeDebugSession) OnDataBreakpointInfoRequest(ctx context.Context, request *dap.DataBreakpointInfoRequest) (*dap.DataBreakpointInfoResponse, error) {
+	// Any variable can be watched for writes.
+	return dap.NewDataBreakpointInfoResponse(request, dap.DataBreakpointInfoResponseBody{
+		DataId:      dap.NullableStringFromString(request.Arguments.Name),
+		Description: request.Arguments.Name,
+		AccessTypes: []dap.DataBreakpointAccessType{dap.DataBreakpointAccessTypeWrite},
+	}), nil
+}
+
+var _ = supports(dap.CommandSetDataBreakpoints, func(c *dap.Capabilities) { c.SupportsDataBreakpoints = true })
+
+func (ds *fakeDebugSession) OnSetDataBreakpointsRequest(ctx context.Context, request *dap.SetDataBreakpointsRequest) (*dap.SetDataBreakpointsResponse, error) {
+	return dap.NewSetDataBreakpointsResponse(request, dap.SetDataBreakpointsResponseBody{
+		Breakpoints: verifiedBreakpoints(len(request.Arguments.Breakpoints)),
+	}), nil
+}
+
+var _ = supports(dap.CommandSetInstructionBreakpoints, func(c *dap.Capabilities) { c.SupportsInstructionBreakpoints = true })
+
+func (ds *fakeDebugSession) OnSetInstructionBreakpointsRequest(ctx context.Context, request *dap.SetInstructionBreakpointsRequest) (*dap.SetInstructionBreakpointsResponse, error) {
+	return dap.NewSetInstructionBreakpointsResponse(request, dap.SetInstructionBreakpointsResponseBody{
+		Breakpoints: verifiedBreakpoints(len(request.Arguments.Breakpoints)),
+	}), nil
+}
+
+// verifiedBreakpoints returns n verified breakpoints.
+func verifiedBreakpoints(n int) []dap.Breakpoint {
+	breakpoints := make([]dap.Breakpoint, n)
+	for i := range breakpoints {
+		breakpoints[i].Verified = true
+	}
+	return breakpoints
 }
 
-func (ds *fakeDebugSession) onContinueRequest(request *dap.ContinueRequest) {
-	response := &dap.ContinueResponse{}
-	response.Response = *newResponse(request.Seq, request.Command)
-	ds.send(response)
-	ds.doContinue()
+func (ds *fakeDebugSession) OnContinueRequest(ctx context.Context, request *dap.ContinueRequest) (*dap.ContinueResponse, error) {
+	if ds.process != nil {
+		args := request.Arguments
+		if err := ds.doResume(ctx, args.ThreadId, args.SingleThread, stepNone); err != nil {
+			return nil, err
+		}
+		return dap.NewContinueResponse(request, dap.ContinueResponseBody{AllThreadsContinued: !args.SingleThread}), nil
+	}
+	ds.s.AfterResponse(ctx, ds.doContinue)
+	return dap.NewContinueResponse(request, dap.ContinueResponseBody{}), nil
 }
 
-func (ds *fakeDebugSession) onNextRequest(request *dap.NextRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "NextRequest is not yet supported"))
+// Unless the program is simulated, stepping is replayed like continuing:
+// the program runs to the next stop of the scenario, whose reason is
+// typically "step".
+
+func (ds *fakeDebugSession) OnNextRequest(ctx context.Context, request *dap.NextRequest) (*dap.NextResponse, error) {
+	if ds.process != nil {
+		if err := ds.doResume(ctx, request.Arguments.ThreadId, request.Arguments.SingleThread, stepOver); err != nil {
+			return nil, err
+		}
+		return dap.NewNextResponse(request), nil
+	}
+	ds.s.AfterResponse(ctx, ds.doContinue)
+	return dap.NewNextResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onStepInRequest(request *dap.StepInRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "StepInRequest is not yet supported"))
+func (ds *fakeDebugSession) OnStepInRequest(ctx context.Context, request *dap.StepInRequest) (*dap.StepInResponse, error) {
+	if ds.process != nil {
+		if err := ds.doResume(ctx, request.Arguments.ThreadId, request.Arguments.SingleThread, stepIn); err != nil {
+			return nil, err
+		}
+		return dap.NewStepInResponse(request), nil
+	}
+	ds.s.AfterResponse(ctx, ds.doContinue)
+	return dap.NewStepInResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onStepOutRequest(request *dap.StepOutRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "StepOutRequest is not yet supported"))
+func (ds *fakeDebugSession) OnStepOutRequest(ctx context.Context, request *dap.StepOutRequest) (*dap.StepOutResponse, error) {
+	if ds.process != nil {
+		if err := ds.doResume(ctx, request.Arguments.ThreadId, request.Arguments.SingleThread, stepOut); err != nil {
+			return nil, err
+		}
+		return dap.NewStepOutResponse(request), nil
+	}
+	ds.s.AfterResponse(ctx, ds.doContinue)
+	return dap.NewStepOutResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onStepBackRequest(request *dap.StepBackRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "StepBackRequest is not yet supported"))
+var _ = supports(dap.CommandStepBack, func(c *dap.Capabilities) { c.SupportsStepBack = true })
+
+func (ds *fakeDebugSession) OnStepBackRequest(ctx context.Context, request *dap.StepBackRequest) (*dap.StepBackResponse, error) {
+	if ds.process != nil {
+		return nil, errCannotReverse
+	}
+	ds.s.AfterResponse(ctx, ds.doReverse)
+	return dap.NewStepBackResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onReverseContinueRequest(request *dap.ReverseContinueRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "ReverseContinueRequest is not yet supported"))
+// errCannotReverse is returned for the requests that run the simulated
+// program backwards, which it cannot do.
+var errCannotReverse = mockError("The program cannot run backwards")
+
+var _ = supports(dap.CommandReverseContinue, func(c *dap.Capabilities) { c.SupportsStepBack = true })
+
+func (ds *fakeDebugSession) OnReverseContinueRequest(ctx context.Context, request *dap.ReverseContinueRequest) (*dap.ReverseContinueResponse, error) {
+	if ds.process != nil {
+		return nil, errCannotReverse
+	}
+	ds.s.AfterResponse(ctx, ds.doReverse)
+	return dap.NewReverseContinueResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onRestartFrameRequest(request *dap.RestartFrameRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "RestartFrameRequest is not yet supported"))
+var _ = supports(dap.CommandRestartFrame, func(c *dap.Capabilities) { c.SupportsRestartFrame = true })
+
+func (ds *fakeDebugSession) OnRestartFrameRequest(ctx context.Context, request *dap.RestartFrameRequest) (*dap.RestartFrameResponse, error) {
+	id := request.Arguments.FrameId
+	if p := ds.process; p != nil {
+		// The thread of the frame runs it again from its first statement.
+		ds.mu.Lock()
+		defer ds.mu.Unlock()
+		t, i := p.frameThread(id)
+		switch {
+		case t == nil:
+			return nil, mockError("No frame %d", id)
+		case p.running:
+			return nil, mockError("Thread %d is running", t.id)
+		}
+		p.restartFrame(t, i)
+		stopped := p.stopped(t, dap.StoppedEventBodyReasonStep)
+		ds.s.AfterResponse(ctx, func() { ds.send(stopped) })
+		return dap.NewRestartFrameResponse(request), nil
+	}
+	threadId, ok := ds.scenario.frameThread(ds.currentStop(), id)
+	if !ok {
+		return nil, mockError("No frame %d", id)
+	}
+	ds.s.AfterResponse(ctx, func() { ds.doStop(dap.StoppedEventBodyReasonStep, threadId) })
+	return dap.NewRestartFrameResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onGotoRequest(request *dap.GotoRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "GotoRequest is not yet supported"))
+var _ = supports(dap.CommandGoto, func(c *dap.Capabilities) { c.SupportsGotoTargetsRequest = true })
+
+func (ds *fakeDebugSession) OnGotoRequest(ctx context.Context, request *dap.GotoRequest) (*dap.GotoResponse, error) {
+	if p := ds.process; p != nil {
+		// The targets are the lines of the statements, and the thread
+		// moves to the target in the function it is in.
+		ds.mu.Lock()
+		defer ds.mu.Unlock()
+		t, err := ds.stoppedThread(request.Arguments.ThreadId)
+		if err != nil {
+			return nil, err
+		}
+		f := t.top()
+		i := f.fn.index(request.Arguments.TargetId)
+		if i < 0 {
+			return nil, mockError("Cannot go to target %d from %s", request.Arguments.TargetId, f.fn.Name)
+		}
+		f.pc = i
+		stopped := p.stopped(t, dap.StoppedEventBodyReasonGoto)
+		ds.s.AfterResponse(ctx, func() { ds.send(stopped) })
+		return dap.NewGotoResponse(request), nil
+	}
+	ds.s.AfterResponse(ctx, func() { ds.doStop(dap.StoppedEventBodyReasonGoto, request.Arguments.ThreadId) })
+	return dap.NewGotoResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onPauseRequest(request *dap.PauseRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "PauseRequest is not yet supported"))
+func (ds *fakeDebugSession) OnPauseRequest(ctx context.Context, request *dap.PauseRequest) (*dap.PauseResponse, error) {
+	id := request.Arguments.ThreadId
+	if p := ds.process; p != nil {
+		// The threads stop where they are.
+		ds.mu.Lock()
+		defer ds.mu.Unlock()
+		t := p.thread(id)
+		switch {
+		case t == nil:
+			return nil, mockError("No thread %d", id)
+		case !p.running:
+			return nil, mockError("Thread %d is not running", id)
+		}
+		stopped := p.stopped(t, dap.StoppedEventBodyReasonPause)
+		ds.s.AfterResponse(ctx, func() { ds.send(stopped) })
+		return dap.NewPauseResponse(request), nil
+	}
+	if !ds.scenario.hasThread(id) {
+		return nil, mockError("No thread %d", id)
+	}
+	ds.s.AfterResponse(ctx, func() { ds.doStop(dap.StoppedEventBodyReasonPause, id) })
+	return dap.NewPauseResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onStackTraceRequest(request *dap.StackTraceRequest) {
-	response := &dap.StackTraceResponse{}
-	response.Response = *newResponse(request.Seq, request.Command)
-	response.Body = dap.StackTraceResponseBody{
-		StackFrames: []dap.StackFrame{
-			{
-				Id:     1000,
-				Source: &dap.Source{Name: "hello.go", Path: "/Users/foo/go/src/hello/hello.go", SourceReference: 0},
-				Line:   5,
-				Column: 0,
-				Name:   "main.main",
-			},
-		},
-		TotalFrames: 1,
+func (ds *fakeDebugSession) OnStackTraceRequest(ctx context.Context, request *dap.StackTraceRequest) (*dap.StackTraceResponse, error) {
+	var frames []dap.StackFrame
+	if p := ds.process; p != nil {
+		ds.mu.Lock()
+		t, err := ds.stoppedThread(request.Arguments.ThreadId)
+		if err == nil {
+			frames = p.stackFrames(t)
+		}
+		ds.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		frames = ds.scenario.stackFrames(ds.currentStop(), request.Arguments.ThreadId)
+	}
+	if request.Arguments.StartFrame < 0 || request.Arguments.Levels < 0 {
+		return nil, mockError("Invalid startFrame %d or levels %d", request.Arguments.StartFrame, request.Arguments.Levels)
+	}
+	total := len(frames)
+	if start := request.Arguments.StartFrame; start < len(frames) {
+		frames = frames[start:]
+	} else {
+		frames = []dap.StackFrame{}
+	}
+	if levels := request.Arguments.Levels; levels > 0 && levels < len(frames) {
+		frames = frames[:levels]
 	}
-	ds.send(response)
+	return dap.NewStackTraceResponse(request, dap.StackTraceResponseBody{StackFrames: frames, TotalFrames: total}), nil
 }
 
-func (ds *fakeDebugSession) onScopesRequest(request *dap.ScopesRequest) {
-	response := &dap.ScopesResponse{}
-	response.Response = *newResponse(request.Seq, request.Command)
-	response.Body = dap.ScopesResponseBody{
-		Scopes: []dap.Scope{
-			{Name: "Local", VariablesReference: 1000, Expensive: false},
-			{Name: "Global", VariablesReference: 1001, Expensive: true},
-		},
+func (ds *fakeDebugSession) OnScopesRequest(ctx context.Context, request *dap.ScopesRequest) (*dap.ScopesResponse, error) {
+	if ds.process != nil {
+		ds.mu.Lock()
+		f, err := ds.frame(request.Arguments.FrameId)
+		ds.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		// The variables reference of the local variables of a frame is
+		// its id.
+		scopes := []dap.Scope{{Name: "Locals", PresentationHint: dap.ScopePresentationHintLocals, VariablesReference: f.id}}
+		return dap.NewScopesResponse(request, dap.ScopesResponseBody{Scopes: scopes}), nil
 	}
-	ds.send(response)
+	scopes := ds.scenario.scopes(ds.currentStop(), request.Arguments.FrameId)
+	return dap.NewScopesResponse(request, dap.ScopesResponseBody{Scopes: scopes}), nil
 }
 
-func (ds *fakeDebugSession) onVariablesRequest(request *dap.VariablesRequest) {
+func (ds *fakeDebugSession) OnVariablesRequest(ctx context.Context, request *dap.VariablesRequest) (*dap.VariablesResponse, error) {
 	select {
-	case <-ds.stopDebug:
-		return
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	// simulate long-running processing to make this handler
 	// respond to this request after the next request is received
 	case <-time.After(100 * time.Millisecond):
-		response := &dap.VariablesResponse{}
-		response.Response = *newResponse(request.Seq, request.Command)
-		response.Body = dap.VariablesResponseBody{
-			Variables: []dap.Variable{{Name: "i", Value: "18434528", EvaluateName: "i", VariablesReference: 0}},
+		if ds.process != nil {
+			ds.mu.Lock()
+			defer ds.mu.Unlock()
+			f := ds.process.frame(request.Arguments.VariablesReference)
+			if f == nil {
+				return nil, mockError("No variables with reference %d", request.Arguments.VariablesReference)
+			}
+			return dap.NewVariablesResponse(request, dap.VariablesResponseBody{Variables: f.variables()}), nil
 		}
-		ds.send(response)
+		variables := ds.scenario.variables(ds.currentStop(), request.Arguments.VariablesReference)
+		return dap.NewVariablesResponse(request, dap.VariablesResponseBody{Variables: variables}), nil
 	}
 }
 
-func (ds *fakeDebugSession) onSetVariableRequest(request *dap.SetVariableRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "setVariableRequest is not yet supported"))
+var _ = supports(dap.CommandSetVariable, func(c *dap.Capabilities) { c.SupportsSetVariable = true })
+
+func (ds *fakeDebugSession) OnSetVariableRequest(ctx context.Context, request *dap.SetVariableRequest) (*dap.SetVariableResponse, error) {
+	if ds.process != nil {
+		ds.mu.Lock()
+		defer ds.mu.Unlock()
+		f := ds.process.frame(request.Arguments.VariablesReference)
+		if f == nil {
+			return nil, mockError("No variables with reference %d", request.Arguments.VariablesReference)
+		}
+		f.locals[request.Arguments.Name] = request.Arguments.Value
+		return dap.NewSetVariableResponse(request, dap.SetVariableResponseBody{Value: request.Arguments.Value}), nil
+	}
+	// The scenario is shared by all sessions, so the new value is
+	// accepted but not remembered.
+	return dap.NewSetVariableResponse(request, dap.SetVariableResponseBody{Value: request.Arguments.Value}), nil
 }
 
-func (ds *fakeDebugSession) onSetExpressionRequest(request *dap.SetExpressionRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "SetExpressionRequest is not yet supported"))
+func (ds *fakeDebugSession) OnSourceRequest(ctx context.Context, request *dap.SourceRequest) (*dap.SourceResponse, error) {
+	ref := request.Arguments.SourceReference
+	if request.Arguments.Source != nil && request.Arguments.Source.SourceReference != 0 {
+		ref = request.Arguments.Source.SourceReference
+	}
+	body, ok := ds.scenario.Sources[ref]
+	if !ok {
+		return nil, mockError("No source with reference %d", ref)
+	}
+	return dap.NewSourceResponse(request, body), nil
 }
 
-func (ds *fakeDebugSession) onSourceRequest(request *dap.SourceRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "SourceRequest is not yet supported"))
+func (ds *fakeDebugSession) OnThreadsRequest(ctx context.Context, request *dap.ThreadsRequest) (*dap.ThreadsResponse, error) {
+	if p := ds.process; p != nil {
+		// Only the threads that have not exited.
+		threads := []dap.Thread{}
+		ds.mu.Lock()
+		for _, t := range p.threads {
+			threads = append(threads, dap.Thread{Id: t.id, Name: t.name})
+		}
+		ds.mu.Unlock()
+		return dap.NewThreadsResponse(request, dap.ThreadsResponseBody{Threads: threads}), nil
+	}
+	return dap.NewThreadsResponse(request, dap.ThreadsResponseBody{Threads: ds.scenario.Threads}), nil
 }
 
-func (ds *fakeDebugSession) onThreadsRequest(request *dap.ThreadsRequest) {
-	response := &dap.ThreadsResponse{}
-	response.Response = *newResponse(request.Seq, request.Command)
-	response.Body = dap.ThreadsResponseBody{Threads: []dap.Thread{{Id: 1, Name: "main"}}}
-	ds.send(response)
+var _ = supports(dap.CommandTerminateThreads, func(c *dap.Capabilities) { c.SupportsTerminateThreadsRequest = true })
 
+func (ds *fakeDebugSession) OnTerminateThreadsRequest(ctx context.Context, request *dap.TerminateThreadsRequest) (*dap.TerminateThreadsResponse, error) {
+	ds.s.AfterResponse(ctx, func() {
+		for _, id := range request.Arguments.ThreadIds {
+			ds.send(dap.NewThreadEvent(dap.ThreadEventBody{Reason: dap.ThreadEventBodyReasonExited, ThreadId: id}))
+		}
+	})
+	return dap.NewTerminateThreadsResponse(request), nil
 }
 
-func (ds *fakeDebugSession) onTerminateThreadsRequest(request *dap.TerminateThreadsRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "TerminateRequest is not yet supported"))
-}
+var _ = supports(dap.CommandModules, func(c *dap.Capabilities) { c.SupportsModulesRequest = true })
 
-func (ds *fakeDebugSession) onEvaluateRequest(request *dap.EvaluateRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "EvaluateRequest is not yet supported"))
+func (ds *fakeDebugSession) OnModulesRequest(ctx context.Context, request *dap.ModulesRequest) (*dap.ModulesResponse, error) {
+	if request.Arguments.StartModule < 0 || request.Arguments.ModuleCount < 0 {
+		return nil, mockError("Invalid startModule %d or moduleCount %d", request.Arguments.StartModule, request.Arguments.ModuleCount)
+	}
+	modules := ds.scenario.Modules
+	total := len(modules)
+	if start := request.Arguments.StartModule; start < len(modules) {
+		modules = modules[start:]
+	} else {
+		modules = []dap.Module{}
+	}
+	if count := request.Arguments.ModuleCount; count > 0 && count < len(modules) {
+		modules = modules[:count]
+	}
+	return dap.NewModulesResponse(request, dap.ModulesResponseBody{Modules: modules, TotalModules: total}), nil
 }
 
-func (ds *fakeDebugSession) onStepInTargetsRequest(request *dap.StepInTargetsRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "StepInTargetRequest is not yet supported"))
-}
+var _ = supports(dap.CommandLoadedSources, func(c *dap.Capabilities) { c.SupportsLoadedSourcesRequest = true })
 
-func (ds *fakeDebugSession) onGotoTargetsRequest(request *dap.GotoTargetsRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "GotoTargetRequest is not yet supported"))
+func (ds *fakeDebugSession) OnLoadedSourcesRequest(ctx context.Context, request *dap.LoadedSourcesRequest) (*dap.LoadedSourcesResponse, error) {
+	return dap.NewLoadedSourcesResponse(request, dap.LoadedSourcesResponseBody{Sources: ds.scenario.loadedSources()}), nil
 }
 
-func (ds *fakeDebugSession) onCompletionsRequest(request *dap.CompletionsRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "CompletionRequest is not yet supported"))
+func (ds *fakeDebugSession) OnEvaluateRequest(ctx context.Context, request *dap.EvaluateRequest) (*dap.EvaluateResponse, error) {
+	// Only variables can be evaluated.
+	if ds.process != nil {
+		ds.mu.Lock()
+		defer ds.mu.Unlock()
+		f, err := ds.frame(request.Arguments.FrameId)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := f.locals[request.Arguments.Expression]
+		if !ok {
+			return nil, mockError("Unable to evaluate %q", request.Arguments.Expression)
+		}
+		return dap.NewEvaluateResponse(request, dap.EvaluateResponseBody{Result: value}), nil
+	}
+	v, ok := ds.scenario.findVariable(ds.currentStop(), request.Arguments.Expression)
+	if !ok {
+		return nil, mockError("Unable to evaluate %q", request.Arguments.Expression)
+	}
+	return dap.NewEvaluateResponse(request, dap.EvaluateResponseBody{
+		Result:             v.Value,
+		Type:               v.Type,
+		VariablesReference: v.VariablesReference,
+	}), nil
 }
 
-func (ds *fakeDebugSession) onExceptionInfoRequest(request *dap.ExceptionInfoRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "ExceptionRequest is not yet supported"))
-}
+var _ = supports(dap.CommandSetExpression, func(c *dap.Capabilities) { c.SupportsSetExpression = true })
 
-func (ds *fakeDebugSession) onLoadedSourcesRequest(request *dap.LoadedSourcesRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "LoadedRequest is not yet supported"))
+func (ds *fakeDebugSession) OnSetExpressionRequest(ctx context.Context, request *dap.SetExpressionRequest) (*dap.SetExpressionResponse, error) {
+	return dap.NewSetExpressionResponse(request, dap.SetExpressionResponseBody{Value: request.Arguments.Value}), nil
 }
 
-func (ds *fakeDebugSession) onDataBreakpointInfoRequest(request *dap.DataBreakpointInfoRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "DataBreakpointInfoRequest is not yet supported"))
-}
+var _ = supports(dap.CommandStepInTargets, func(c *dap.Capabilities) { c.SupportsStepInTargetsRequest = true })
 
-func (ds *fakeDebugSession) onSetDataBreakpointsRequest(request *dap.SetDataBreakpointsRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "SetDataBreakpointsRequest is not yet supported"))
+func (ds *fakeDebugSession) OnStepInTargetsRequest(ctx context.Context, request *dap.StepInTargetsRequest) (*dap.StepInTargetsResponse, error) {
+	if ds.process != nil {
+		ds.mu.Lock()
+		defer ds.mu.Unlock()
+		f, err := ds.frame(request.Arguments.FrameId)
+		if err != nil {
+			return nil, err
+		}
+		targets := []dap.StepInTarget{}
+		if s := f.statement(); s.Call != "" {
+			targets = append(targets, dap.StepInTarget{Id: 1, Label: s.Call, Line: s.Line})
+		}
+		return dap.NewStepInTargetsResponse(request, dap.StepInTargetsResponseBody{Targets: targets}), nil
+	}
+	// There are no calls to step into.
+	return dap.NewStepInTargetsResponse(request, dap.StepInTargetsResponseBody{Targets: []dap.StepInTarget{}}), nil
 }
 
-func (ds *fakeDebugSession) onReadMemoryRequest(request *dap.ReadMemoryRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "ReadMemoryRequest is not yet supported"))
-}
+var _ = supports(dap.CommandGotoTargets, func(c *dap.Capabilities) { c.SupportsGotoTargetsRequest = true })
 
-func (ds *fakeDebugSession) onDisassembleRequest(request *dap.DisassembleRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "DisassembleRequest is not yet supported"))
+func (ds *fakeDebugSession) OnGotoTargetsRequest(ctx context.Context, request *dap.GotoTargetsRequest) (*dap.GotoTargetsResponse, error) {
+	line := request.Arguments.Line
+	if p := ds.process; p != nil {
+		// The target of a line with a statement is identified by the line.
+		targets := []dap.GotoTarget{}
+		if p.prog.isSource(request.Arguments.Source) && p.prog.lineHasStatement(line) {
+			targets = append(targets, dap.GotoTarget{Id: line, Label: fmt.Sprintf("line %d", line), Line: line})
+		}
+		return dap.NewGotoTargetsResponse(request, dap.GotoTargetsResponseBody{Targets: targets}), nil
+	}
+	return dap.NewGotoTargetsResponse(request, dap.GotoTargetsResponseBody{
+		Targets: []dap.GotoTarget{{Id: 1, Label: fmt.Sprintf("line %d", line), Line: line}},
+	}), nil
 }
 
-func (ds *fakeDebugSession) onCancelRequest(request *dap.CancelRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "CancelRequest is not yet supported"))
+var _ = supports(dap.CommandCompletions, func(c *dap.Capabilities) { c.SupportsCompletionsRequest = true })
+
+func (ds *fakeDebugSession) OnCompletionsRequest(ctx context.Context, request *dap.CompletionsRequest) (*dap.CompletionsResponse, error) {
+	// Complete the names of the variables.
+	targets := []dap.CompletionItem{}
+	for _, name := range ds.scenario.variableNames(ds.currentStop()) {
+		if strings.HasPrefix(name, request.Arguments.Text) {
+			targets = append(targets, dap.CompletionItem{Label: name, Type: dap.CompletionItemTypeVariable})
+		}
+	}
+	return dap.NewCompletionsResponse(request, dap.CompletionsResponseBody{Targets: targets}), nil
 }
 
-func (ds *fakeDebugSession) onBreakpointLocationsRequest(request *dap.BreakpointLocationsRequest) {
-	ds.send(newErrorResponse(request.Seq, request.Command, "BreakpointLocationsRequest is not yet supported"))
+var _ = supports(dap.CommandExceptionInfo, func(c *dap.Capabilities) { c.SupportsExceptionInfoRequest = true })
+
+func (ds *fakeDebugSession) OnExceptionInfoRequest(ctx context.Context, request *dap.ExceptionInfoRequest) (*dap.ExceptionInfoResponse, error) {
+	body := dap.ExceptionInfoResponseBody{ExceptionId: "exception", BreakMode: dap.ExceptionBreakModeUnhandled}
+	if stop := ds.currentStop(); stop != nil {
+		body.Description = stop.Description
+	}
+	return dap.NewExceptionInfoResponse(request, body), nil
 }
 
-func newEvent(event string) *dap.Event {
-	return &dap.Event{
-		ProtocolMessage: dap.ProtocolMessage{
-			Seq:  0,
-			Type: "event",
-		},
-		Event: event,
+var _ = supports(dap.CommandReadMemory, func(c *dap.Capabilities) { c.SupportsReadMemoryRequest = true })
+
+func (ds *fakeDebugSession) OnReadMemoryRequest(ctx context.Context, request *dap.ReadMemoryRequest) (*dap.ReadMemoryResponse, error) {
+	// The memory is all zeros.
+	if count := request.Arguments.Count; count < 0 || count > maxMemoryCount {
+		return nil, mockError("Cannot read %d bytes, at most %d can be read at once", count, maxMemoryCount)
 	}
+	return dap.NewReadMemoryResponse(request, dap.ReadMemoryResponseBody{
+		Address: request.Arguments.MemoryReference,
+		Data:    base64.StdEncoding.EncodeToString(make([]byte, request.Arguments.Count)),
+	}), nil
 }
 
-func newResponse(requestSeq int, command string) *dap.Response {
-	return &dap.Response{
-		ProtocolMessage: dap.ProtocolMessage{
-			Seq:  0,
-			Type: "response",
-		},
-		Command:    command,
-		RequestSeq: requestSeq,
-		Success:    true,
+var _ = supports(dap.CommandWriteMemory, func(c *dap.Capabilities) { c.SupportsWriteMemoryRequest = true })
+
+func (ds *fakeDebugSession) OnWriteMemoryRequest(ctx context.Context, request *dap.WriteMemoryRequest) (*dap.WriteMemoryResponse, error) {
+	data, err := base64.StdEncoding.DecodeString(request.Arguments.Data)
+	if err != nil {
+		return nil, mockError("Invalid data: %v", err)
 	}
+	return dap.NewWriteMemoryResponse(request, dap.WriteMemoryResponseBody{BytesWritten: len(data)}), nil
 }
 
-func newErrorResponse(requestSeq int, command string, message string) *dap.ErrorResponse {
-	er := &dap.ErrorResponse{}
-	er.Response = *newResponse(requestSeq, command)
-	er.Success = false
-	er.Message = "unsupported"
-	er.Body.Error.Format = message
-	er.Body.Error.Id = 12345
-	return er
+var _ = supports(dap.CommandDisassemble, func(c *dap.Capabilities) { c.SupportsDisassembleRequest = true })
+
+func (ds *fakeDebugSession) OnDisassembleRequest(ctx context.Context, request *dap.DisassembleRequest) (*dap.DisassembleResponse, error) {
+	// The memory is all zeros, which disassemble to no-ops.
+	if count := request.Arguments.InstructionCount; count < 0 || count > maxInstructionCount {
+		return nil, mockError("Cannot disassemble %d instructions, at most %d can be disassembled at once", count, maxInstructionCount)
+	}
+	instructions := make([]dap.DisassembledInstruction, request.Arguments.InstructionCount)
+	for i := range instructions {
+		instructions[i] = dap.DisassembledInstruction{Address: fmt.Sprintf("0x%x", request.Arguments.InstructionOffset+i), Instruction: "nop"}
+	}
+	return dap.NewDisassembleResponse(request, dap.DisassembleResponseBody{Instructions: instructions}), nil
 }
@@ -17,9 +17,14 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
+	"path/filepath"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -28,42 +33,42 @@ import (
 )
 
 var initializeRequest = []byte(`{"seq":1,"type":"request","command":"initialize","arguments":{"clientID":"vscode","clientName":"Visual Studio Code","adapterID":"go","pathFormat":"path","linesStartAt1":true,"columnsStartAt1":true,"supportsVariableType":true,"supportsVariablePaging":true,"supportsRunInTerminalRequest":true,"locale":"en-us"}}`)
-var initializedEvent = []byte(`{"seq":0,"type":"event","event":"initialized"}`)
-var initializeResponse = []byte(`{"seq":0,"type":"response","request_seq":1,"success":true,"command":"initialize","body":{"supportsConfigurationDoneRequest":true}}`)
+var initializeResponse = []byte(`{"seq":1,"type":"response","request_seq":1,"success":true,"command":"initialize","body":{"supportsConfigurationDoneRequest":true,"supportsFunctionBreakpoints":true,"supportsStepBack":true,"supportsSetVariable":true,"supportsRestartFrame":true,"supportsGotoTargetsRequest":true,"supportsStepInTargetsRequest":true,"supportsCompletionsRequest":true,"supportsModulesRequest":true,"supportsRestartRequest":true,"supportsExceptionInfoRequest":true,"supportsLoadedSourcesRequest":true,"supportsTerminateThreadsRequest":true,"supportsSetExpression":true,"supportsTerminateRequest":true,"supportsDataBreakpoints":true,"supportsReadMemoryRequest":true,"supportsWriteMemoryRequest":true,"supportsDisassembleRequest":true,"supportsCancelRequest":true,"supportsBreakpointLocationsRequest":true,"supportsInstructionBreakpoints":true}}`)
+var initializedEvent = []byte(`{"seq":2,"type":"event","event":"initialized"}`)
 
 var launchRequest = []byte(`{"seq":2,"type":"request","command":"launch","arguments":{"noDebug": true,"name":"Launch","type":"go","request":"launch","mode":"debug","program":"/Users/foo/go/src/hello","__sessionId":"4c88179f-1202-4f75-9e67-5bf535cde30a","args":["somearg"],"env":{"GOPATH":"/Users/foo/go","HOME":"/Users/foo","SHELL":"/bin/bash"}}}`)
-var launchResponse = []byte(`{"seq":0,"type":"response","request_seq":2,"success":true,"command":"launch"}`)
+var launchResponse = []byte(`{"seq":3,"type":"response","request_seq":2,"success":true,"command":"launch"}`)
 
 var setBreakpointsRequest = []byte(`{"seq":3,"type":"request","command":"setBreakpoints","arguments":{"source":{"name":"hello.go","path":"/Users/foo/go/src/hello/hello.go"},"lines":[7],"breakpoints":[{"line":7}],"sourceModified":false}}`)
-var setBreakpointsResponse = []byte(`{"seq":0,"type":"response","request_seq":3,"success":true,"command":"setBreakpoints","body":{"breakpoints":[{"verified":true,"line":7}]}}`)
+var setBreakpointsResponse = []byte(`{"seq":4,"type":"response","request_seq":3,"success":true,"command":"setBreakpoints","body":{"breakpoints":[{"verified":true,"line":7}]}}`)
 
 var setExceptionBreakpointsRequest = []byte(`{"seq":4,"type":"request","command":"setExceptionBreakpoints","arguments":{"filters":[]}}`)
-var setExceptionBreakpointsResponse = []byte(`{"seq":0,"type":"response","request_seq":4,"success":true,"command":"setExceptionBreakpoints","body":{}}`)
+var setExceptionBreakpointsResponse = []byte(`{"seq":5,"type":"response","request_seq":4,"success":true,"command":"setExceptionBreakpoints","body":{}}`)
 
 var configurationDoneRequest = []byte(`{"seq":5,"type":"request","command":"configurationDone"}`)
-var threadEvent = []byte(`{"seq":0,"type":"event","event":"thread","body":{"reason":"started","threadId":1}}`)
-var configurationDoneResponse = []byte(`{"seq":0,"type":"response","request_seq":5,"success":true,"command":"configurationDone"}`)
+var threadEvent = []byte(`{"seq":6,"type":"event","event":"thread","body":{"reason":"started","threadId":1}}`)
+var configurationDoneResponse = []byte(`{"seq":7,"type":"response","request_seq":5,"success":true,"command":"configurationDone"}`)
 
-var stoppedEvent = []byte(`{"seq":0,"type":"event","event":"stopped","body":{"reason":"breakpoint","threadId":1,"allThreadsStopped":true}}`)
+var stoppedEvent = []byte(`{"seq":8,"type":"event","event":"stopped","body":{"reason":"breakpoint","threadId":1,"allThreadsStopped":true}}`)
 
 var threadsRequest = []byte(`{"seq":6,"type":"request","command":"threads"}`)
-var threadsResponse = []byte(`{"seq":0,"type":"response","request_seq":6,"success":true,"command":"threads","body":{"threads":[{"id":1,"name":"main"}]}}`)
+var threadsResponse = []byte(`{"seq":9,"type":"response","request_seq":6,"success":true,"command":"threads","body":{"threads":[{"id":1,"name":"main"}]}}`)
 
 var stackTraceRequest = []byte(`{"seq":7,"type":"request","command":"stackTrace","arguments":{"threadId":1,"startFrame":0,"levels":20}}`)
-var stackTraceResponse = []byte(`{"seq":0,"type":"response","request_seq":7,"success":true,"command":"stackTrace","body":{"stackFrames":[{"id":1000,"name":"main.main","source":{"name":"hello.go","path":"/Users/foo/go/src/hello/hello.go"},"line":5,"column":0}],"totalFrames":1}}`)
+var stackTraceResponse = []byte(`{"seq":10,"type":"response","request_seq":7,"success":true,"command":"stackTrace","body":{"stackFrames":[{"id":1000,"name":"main.main","source":{"name":"hello.go","path":"/Users/foo/go/src/hello/hello.go"},"line":5,"column":0}],"totalFrames":1}}`)
 
 var scopesRequest = []byte(`{"seq":8,"type":"request","command":"scopes","arguments":{"frameId":1000}}`)
-var scopesResponse = []byte(`{"seq":0,"type":"response","request_seq":8,"success":true,"command":"scopes","body":{"scopes":[{"name":"Local","variablesReference":1000,"expensive":false},{"name":"Global","variablesReference":1001,"expensive":true}]}}`)
+var scopesResponse = []byte(`{"seq":11,"type":"response","request_seq":8,"success":true,"command":"scopes","body":{"scopes":[{"name":"Local","variablesReference":1000,"expensive":false},{"name":"Global","variablesReference":1001,"expensive":true}]}}`)
 
 var variablesRequest = []byte(`{"seq":9,"type":"request","command":"variables","arguments":{"variablesReference":1000}}`)
-var variablesResponse = []byte(`{"seq":0,"type":"response","request_seq":9,"success":true,"command":"variables","body":{"variables":[{"name":"i","value":"18434528","evaluateName":"i","variablesReference":0}]}}`)
+var variablesResponse = []byte(`{"seq":13,"type":"response","request_seq":9,"success":true,"command":"variables","body":{"variables":[{"name":"i","value":"18434528","evaluateName":"i","variablesReference":0}]}}`)
 
 var continueRequest = []byte(`{"seq":10,"type":"request","command":"continue","arguments":{"threadId":1}}`)
-var continueResponse = []byte(`{"seq":0,"type":"response","request_seq":10,"success":true,"command":"continue","body":{"allThreadsContinued":false}}`)
+var continueResponse = []byte(`{"seq":12,"type":"response","request_seq":10,"success":true,"command":"continue","body":{"allThreadsContinued":false}}`)
 
-var terminatedEvent = []byte(`{"seq":0,"type":"event","event":"terminated","body":{}}`)
+var terminatedEvent = []byte(`{"seq":14,"type":"event","event":"terminated","body":{}}`)
 var disconnectRequest = []byte(`{"seq":11,"type":"request","command":"disconnect","arguments":{"restart":false}}`)
-var disconnectResponse = []byte(`{"seq":0,"type":"response","request_seq":11,"success":true,"command":"disconnect"}`)
+var disconnectResponse = []byte(`{"seq":15,"type":"response","request_seq":11,"success":true,"command":"disconnect"}`)
 
 func expectMessage(t *testing.T, r *bufio.Reader, want []byte) {
 	got, err := dap.ReadBaseMessage(r)
@@ -77,42 +82,75 @@ func expectMessage(t *testing.T, r *bufio.Reader, want []byte) {
 
 func TestServer(t *testing.T) {
 	log.SetOutput(ioutil.Discard)
-	port := "54321"
+	for _, network := range []string{"tcp", "unix"} {
+		t.Run(network, func(t *testing.T) {
+			address := "127.0.0.1:0"
+			if network == "unix" {
+				address = filepath.Join(t.TempDir(), "dap.sock")
+			}
+			listener, err := net.Listen(network, address)
+			if err != nil {
+				t.Fatal("Could not start server:", err)
+			}
+			done := make(chan error)
+			go func() {
+				done <- serve(listener, defaultScenario)
+			}()
+
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go dialClient(t, listener.Addr(), &wg)
+			go dialClient(t, listener.Addr(), &wg)
+			wg.Wait()
+
+			listener.Close()
+			if err := <-done; !errors.Is(err, net.ErrClosed) {
+				t.Errorf("got err=%v, want net.ErrClosed", err)
+			}
+		})
+	}
+}
+
+func TestServerStdio(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	done := make(chan struct{})
 	go func() {
-		err := server(port)
-		if err != nil {
-			log.Fatal("Could not start server:", err)
-		}
+		handleConnection(stdioConn{stdinR, stdoutW}, defaultScenario)
+		close(done)
 	}()
-	// Give server time to start listening before clients connect
-	time.Sleep(100 * time.Millisecond)
-
-	var wg sync.WaitGroup
-	wg.Add(2)
-	go client(t, port, &wg)
-	go client(t, port, &wg)
-	wg.Wait()
+
+	client(t, stdinW, stdoutR)
+	stdinW.Close()
+	<-done
 }
 
-func client(t *testing.T, port string, wg *sync.WaitGroup) {
+func dialClient(t *testing.T, addr net.Addr, wg *sync.WaitGroup) {
 	defer wg.Done()
-	conn, err := net.Dial("tcp", ":"+port)
+	conn, err := net.Dial(addr.Network(), addr.String())
 	if err != nil {
-		log.Fatal("Could not connect to server:", err)
+		t.Error("Could not connect to server:", err)
+		return
 	}
 	defer func() {
 		t.Log("Closing connection to server at", conn.RemoteAddr())
 		conn.Close()
 	}()
 	t.Log("Connected to server at", conn.RemoteAddr())
+	client(t, conn, conn)
+}
 
-	r := bufio.NewReader(conn)
+// client runs a debug session, writing requests to conn and reading the
+// events and responses from rd.
+func client(t *testing.T, conn io.Writer, rd io.Reader) {
+	r := bufio.NewReader(rd)
 
 	// Start up
 
 	dap.WriteBaseMessage(conn, initializeRequest)
-	expectMessage(t, r, initializedEvent)
 	expectMessage(t, r, initializeResponse)
+	expectMessage(t, r, initializedEvent)
 
 	dap.WriteBaseMessage(conn, launchRequest)
 	expectMessage(t, r, launchResponse)
@@ -154,3 +192,230 @@ func client(t *testing.T, port string, wg *sync.WaitGroup) {
 	dap.WriteBaseMessage(conn, disconnectRequest)
 	expectMessage(t, r, disconnectResponse)
 }
+
+func TestServerProtocolErrors(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	serverConn, clientConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		handleConnection(serverConn, defaultScenario)
+		close(done)
+	}()
+	r := bufio.NewReader(clientConn)
+	expectError := func(requestSeq int, command string) {
+		t.Helper()
+		msg, err := dap.ReadProtocolMessage(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, ok := msg.(*dap.ErrorResponse)
+		if !ok || resp.RequestSeq != requestSeq || resp.Command != command || resp.Success {
+			t.Errorf("got %#v, want error response to %s request %d", msg, command, requestSeq)
+		}
+	}
+
+	// A request with an unknown command.
+	dap.WriteBaseMessage(clientConn, []byte(`{"seq":1,"type":"request","command":"foo"}`))
+	expectError(1, "foo")
+	// A request whose arguments cannot be decoded.
+	dap.WriteBaseMessage(clientConn, []byte(`{"seq":2,"type":"request","command":"continue","arguments":{"threadId":"x"}}`))
+	expectError(2, "continue")
+	// Messages that cannot be answered are dropped, and so are malformed
+	// headers along with the content that follows them.
+	dap.WriteBaseMessage(clientConn, []byte(`{"seq":4,"type":"event","event":"initialized"}`))
+	dap.WriteBaseMessage(clientConn, []byte(`not json`))
+	clientConn.Write([]byte("Content-Type: text\r\n\r\n{}"))
+	dap.WriteBaseMessage(clientConn, []byte(`{"seq":5,"type":"request","command":"threads"}`))
+	msg, err := dap.ReadProtocolMessage(r)
+	if resp, ok := msg.(*dap.ThreadsResponse); err != nil || !ok || resp.RequestSeq != 5 {
+		t.Errorf("got %#v, err=%v, want threads response", msg, err)
+	}
+
+	clientConn.Close()
+	<-done
+}
+
+func TestServerTerminatesOnce(t *testing.T) {
+	pc := initializeScenario(t, defaultScenario)
+	pc.call(dap.NewLaunchRequest(nil))
+	pc.call(dap.NewConfigurationDoneRequest(nil))
+	pc.expect(dap.NewThreadEvent(dap.ThreadEventBody{Reason: dap.ThreadEventBodyReasonStarted, ThreadId: 1}))
+	pc.expect(dap.NewTerminatedEvent(dap.TerminatedEventBody{}))
+
+	// Neither continuing nor terminating the program again terminates it
+	// again.
+	pc.call(dap.NewContinueRequest(dap.ContinueArguments{ThreadId: 1}))
+	pc.call(dap.NewTerminateRequest(nil))
+	pc.call(dap.NewPauseRequest(dap.PauseArguments{ThreadId: 1}))
+	pc.expect(dap.NewStoppedEvent(dap.StoppedEventBody{Reason: dap.StoppedEventBodyReasonPause, ThreadId: 1, AllThreadsStopped: true}))
+	select {
+	case e := <-pc.c.Events():
+		t.Errorf("got %#v, want no more events", e)
+	case <-time.After(1500 * time.Millisecond):
+	}
+}
+
+func TestServerHandlesEveryRequest(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	serverConn, clientConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		handleConnection(serverConn, defaultScenario)
+		close(done)
+	}()
+	c := dap.NewClient(clientConn)
+	go func() {
+		for range c.Events() {
+		}
+	}()
+
+	// Requests that fail with empty arguments, which refer to nothing.
+	wantError := map[string]bool{"source": true, "evaluate": true, "pause": true, "restartFrame": true}
+	handler := reflect.TypeOf((*dap.Handler)(nil)).Elem()
+	for i := 0; i < handler.NumMethod(); i++ {
+		m := handler.Method(i)
+		req := reflect.New(m.Type.In(1).Elem()).Interface().(dap.RequestMessage)
+		resp, err := c.Call(req)
+		command := req.GetRequest().Command
+		if wantError[command] {
+			if !errors.As(err, new(*dap.ResponseError)) {
+				t.Errorf("%s: got %#v, err=%v, want error response", command, resp, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: got err=%v", command, err)
+			continue
+		}
+		if got, want := reflect.TypeOf(resp), m.Type.Out(0); got != want {
+			t.Errorf("%s: got %v, want %v", command, got, want)
+		}
+	}
+
+	c.Close()
+	<-done
+}
+
+func TestServerRejectsBadArguments(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	serverConn, clientConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		handleConnection(serverConn, defaultScenario)
+		close(done)
+	}()
+	c := dap.NewClient(clientConn)
+	go func() {
+		for range c.Events() {
+		}
+	}()
+
+	for _, req := range []dap.RequestMessage{
+		dap.NewStackTraceRequest(dap.StackTraceArguments{ThreadId: 1, StartFrame: -1}),
+		dap.NewStackTraceRequest(dap.StackTraceArguments{ThreadId: 1, Levels: -1}),
+		dap.NewModulesRequest(dap.ModulesArguments{StartModule: -1}),
+		dap.NewModulesRequest(dap.ModulesArguments{ModuleCount: -1}),
+		dap.NewReadMemoryRequest(dap.ReadMemoryArguments{MemoryReference: "0x0", Count: -1}),
+		dap.NewReadMemoryRequest(dap.ReadMemoryArguments{MemoryReference: "0x0", Count: maxMemoryCount + 1}),
+		dap.NewDisassembleRequest(dap.DisassembleArguments{MemoryReference: "0x0", InstructionCount: -1}),
+		dap.NewDisassembleRequest(dap.DisassembleArguments{MemoryReference: "0x0", InstructionCount: maxInstructionCount + 1}),
+	} {
+		// The fake debugger rejects the arguments, rather than panicking.
+		resp, err := c.Call(req)
+		var de *dap.Error
+		if !errors.As(err, &de) || de.Id != 12345 {
+			t.Errorf("%#v: got %#v, err=%v, want mock error response", req, resp, err)
+		}
+	}
+
+	c.Close()
+	<-done
+}
+
+func TestCapabilities(t *testing.T) {
+	// Every capability registered for a request is set.
+	got := reflect.ValueOf(capabilities())
+	for command, set := range requestCapabilities {
+		var want dap.Capabilities
+		set(&want)
+		w := reflect.ValueOf(want)
+		n := 0
+		for i := 0; i < w.NumField(); i++ {
+			if w.Field(i).Kind() != reflect.Bool || !w.Field(i).Bool() {
+				continue
+			}
+			n++
+			if !got.Field(i).Bool() {
+				t.Errorf("%s: %s is not set", command, w.Type().Field(i).Name)
+			}
+		}
+		if n == 0 {
+			t.Errorf("%s: no capability is registered", command)
+		}
+	}
+}
+
+func TestCapabilitiesMatchHandlers(t *testing.T) {
+	pc := initializeScenario(t, defaultScenario)
+	pc.call(dap.NewLaunchRequest(nil))
+	pc.call(dap.NewConfigurationDoneRequest(nil))
+
+	// The requests that clients may only send if the capability says that
+	// they are supported, with valid arguments. A capability is set if and
+	// only if the handlers of its requests respond successfully.
+	source := &dap.Source{Name: "hello.go", Path: "/tmp/hello.go"}
+	tests := []struct {
+		req        dap.RequestMessage
+		capability string
+	}{
+		{dap.NewSetFunctionBreakpointsRequest(dap.SetFunctionBreakpointsArguments{Breakpoints: []dap.FunctionBreakpoint{{Name: "main"}}}), "SupportsFunctionBreakpoints"},
+		{dap.NewStepBackRequest(dap.StepBackArguments{ThreadId: 1}), "SupportsStepBack"},
+		{dap.NewReverseContinueRequest(dap.ReverseContinueArguments{ThreadId: 1}), "SupportsStepBack"},
+		{dap.NewSetVariableRequest(dap.SetVariableArguments{VariablesReference: 1000, Name: "i", Value: "1"}), "SupportsSetVariable"},
+		{dap.NewRestartFrameRequest(dap.RestartFrameArguments{FrameId: 1000}), "SupportsRestartFrame"},
+		{dap.NewGotoTargetsRequest(dap.GotoTargetsArguments{Source: *source, Line: 5}), "SupportsGotoTargetsRequest"},
+		{dap.NewGotoRequest(dap.GotoArguments{ThreadId: 1, TargetId: 1}), "SupportsGotoTargetsRequest"},
+		{dap.NewStepInTargetsRequest(dap.StepInTargetsArguments{FrameId: 1000}), "SupportsStepInTargetsRequest"},
+		{dap.NewCompletionsRequest(dap.CompletionsArguments{Text: "i", Column: 2}), "SupportsCompletionsRequest"},
+		{dap.NewModulesRequest(dap.ModulesArguments{}), "SupportsModulesRequest"},
+		{dap.NewExceptionInfoRequest(dap.ExceptionInfoArguments{ThreadId: 1}), "SupportsExceptionInfoRequest"},
+		{dap.NewLoadedSourcesRequest(nil), "SupportsLoadedSourcesRequest"},
+		{dap.NewTerminateThreadsRequest(dap.TerminateThreadsArguments{ThreadIds: []int{1}}), "SupportsTerminateThreadsRequest"},
+		{dap.NewSetExpressionRequest(dap.SetExpressionArguments{Expression: "i", Value: "1"}), "SupportsSetExpression"},
+		{dap.NewDataBreakpointInfoRequest(dap.DataBreakpointInfoArguments{Name: "i"}), "SupportsDataBreakpoints"},
+		{dap.NewSetDataBreakpointsRequest(dap.SetDataBreakpointsArguments{Breakpoints: []dap.DataBreakpoint{{DataId: "i"}}}), "SupportsDataBreakpoints"},
+		{dap.NewReadMemoryRequest(dap.ReadMemoryArguments{MemoryReference: "0x1000", Count: 4}), "SupportsReadMemoryRequest"},
+		{dap.NewWriteMemoryRequest(dap.WriteMemoryArguments{MemoryReference: "0x1000", Data: "AAAA"}), "SupportsWriteMemoryRequest"},
+		{dap.NewDisassembleRequest(dap.DisassembleArguments{MemoryReference: "0x1000", InstructionCount: 2}), "SupportsDisassembleRequest"},
+		{dap.NewCancelRequest(&dap.CancelArguments{RequestId: 1 << 20}), "SupportsCancelRequest"},
+		{dap.NewBreakpointLocationsRequest(&dap.BreakpointLocationsArguments{Source: *source, Line: 5}), "SupportsBreakpointLocationsRequest"},
+		{dap.NewSetInstructionBreakpointsRequest(dap.SetInstructionBreakpointsArguments{Breakpoints: []dap.InstructionBreakpoint{{InstructionReference: "0x1000"}}}), "SupportsInstructionBreakpoints"},
+		{dap.NewRestartRequest(nil), "SupportsRestartRequest"},
+		{dap.NewTerminateRequest(nil), "SupportsTerminateRequest"},
+	}
+	caps := reflect.ValueOf(capabilities())
+	tested := map[string]bool{dap.CommandConfigurationDone: true}
+	for _, test := range tests {
+		command := test.req.GetRequest().Command
+		tested[command] = true
+		supported := caps.FieldByName(test.capability).Bool()
+		resp, err := pc.c.Call(test.req)
+		if supported {
+			if err != nil {
+				t.Errorf("%s: %s is set, but got error %v", command, test.capability, err)
+			} else if r := resp.GetResponse(); !r.Success || r.Command != command {
+				t.Errorf("%s: got %#v, want a successful response", command, r)
+			}
+			continue
+		}
+		var re *dap.ResponseError
+		if !errors.As(err, &re) || re.Response.Message != fmt.Sprintf("%s %v", command, dap.ErrUnsupportedRequest) {
+			t.Errorf("%s: %s is not set, but got %#v, %v", command, test.capability, resp, err)
+		}
+	}
+	for command := range requestCapabilities {
+		if !tested[command] {
+			t.Errorf("%s: no request is tested", command)
+		}
+	}
+}
@@ -43,6 +43,11 @@ type Codec struct {
 	eventCtor    map[string]messageCtor
 	requestCtor  map[string]messageCtor
 	responseCtor map[string]messageCtor
+
+	// genericFallback is set by UseGenericFallback.
+	genericFallback bool
+	// strict is set by UseStrictDecoding.
+	strict bool
 }
 
 // NewCodec constructs a new codec that extends the vanilla DAP protocol.
@@ -104,6 +109,46 @@ func (c *Codec) RegisterEvent(event string, ctor func() Message) error {
 	return nil
 }
 
+// UseGenericFallback makes DecodeMessage decode requests, successful
+// responses and events that are not registered into GenericRequest,
+// GenericResponse and GenericEvent, instead of returning a
+// DecodeProtocolMessageFieldError. This lets proxies and loggers pass
+// through vendor extensions of the protocol unchanged.
+func (c *Codec) UseGenericFallback() {
+	c.genericFallback = true
+}
+
+// UseStrictDecoding makes DecodeMessage check that messages have no
+// fields unknown to their Go types, with names matched exactly rather than
+// case-insensitively as json.Unmarshal does, and have all the properties
+// the specification marks as required. Messages that fail the checks are
+// returned along with a *StrictDecodeError that lists the offending fields.
+// This is meant to validate the traffic of an implementation, such as in
+// tests, as peers commonly rely on the leniency of the protocol.
+func (c *Codec) UseStrictDecoding() {
+	c.strict = true
+}
+
+// NewResponse returns an empty successful response to req, of the type
+// registered for its command, with Type, Command and RequestSeq filled in.
+// The response to a command that is not registered is a *GenericResponse.
+func (c *Codec) NewResponse(req RequestMessage) ResponseMessage {
+	r := req.GetRequest()
+	var resp ResponseMessage
+	if ctor, ok := c.responseCtor[r.Command]; ok {
+		resp, _ = ctor().(ResponseMessage)
+	}
+	if resp == nil {
+		resp = &GenericResponse{}
+	}
+	rr := resp.GetResponse()
+	rr.Type = "response"
+	rr.Command = r.Command
+	rr.RequestSeq = r.Seq
+	rr.Success = true
+	return resp
+}
+
 // DecodeMessage parses the JSON-encoded data and returns the result of
 // the appropriate type within the ProtocolMessage hierarchy. If message type,
 // command, etc cannot be cast, returns DecodeProtocolMessageFieldError.
@@ -138,11 +183,14 @@ func (c *Codec) DecodeMessage(data []byte) (Message, error) {
 // struct to be returned.
 func (c *Codec) decodeRequest(command string, seq int, data []byte) (Message, error) {
 	ctor, ok := c.requestCtor[command]
+	if !ok && c.genericFallback {
+		ctor, ok = func() Message { return &GenericRequest{} }, true
+	}
 	if !ok {
 		return nil, &DecodeProtocolMessageFieldError{seq, "Request", "command", command, json.RawMessage(data)}
 	}
 	requestPtr := ctor()
-	err := json.Unmarshal(data, requestPtr)
+	err := c.unmarshal(seq, data, requestPtr)
 	return requestPtr, err
 }
 
@@ -152,15 +200,18 @@ func (c *Codec) decodeRequest(command string, seq int, data []byte) (Message, er
 func (c *Codec) decodeResponse(command string, seq int, success bool, data []byte) (Message, error) {
 	if !success {
 		var er ErrorResponse
-		err := json.Unmarshal(data, &er)
+		err := c.unmarshal(seq, data, &er)
 		return &er, err
 	}
 	ctor, ok := c.responseCtor[command]
+	if !ok && c.genericFallback {
+		ctor, ok = func() Message { return &GenericResponse{} }, true
+	}
 	if !ok {
 		return nil, &DecodeProtocolMessageFieldError{seq, "Response", "command", command, json.RawMessage(data)}
 	}
 	responsePtr := ctor()
-	err := json.Unmarshal(data, responsePtr)
+	err := c.unmarshal(seq, data, responsePtr)
 	return responsePtr, err
 }
 
@@ -169,14 +220,26 @@ func (c *Codec) decodeResponse(command string, seq int, success bool, data []byt
 // struct to be returned.
 func (c *Codec) decodeEvent(event string, seq int, data []byte) (Message, error) {
 	ctor, ok := c.eventCtor[event]
+	if !ok && c.genericFallback {
+		ctor, ok = func() Message { return &GenericEvent{} }, true
+	}
 	if !ok {
 		return nil, &DecodeProtocolMessageFieldError{seq, "Event", "event", event, json.RawMessage(data)}
 	}
 	eventPtr := ctor()
-	err := json.Unmarshal(data, eventPtr)
+	err := c.unmarshal(seq, data, eventPtr)
 	return eventPtr, err
 }
 
+// unmarshal uses json.Unmarshal to populate message from data, checking
+// data first if the codec is strict.
+func (c *Codec) unmarshal(seq int, data []byte, message Message) error {
+	if err := json.Unmarshal(data, message); err != nil || !c.strict {
+		return err
+	}
+	return checkStrict(seq, data, message)
+}
+
 // DecodeProtocolMessage parses the JSON-encoded ProtocolMessage and returns
 // the message embedded in it. If message type, command, etc cannot be cast,
 // returns DecodeProtocolMessageFieldError. See also godoc for json.Unmarshal,
@@ -15,7 +15,10 @@
 package dap
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -267,7 +270,7 @@ var dataBreakpointInfoResponseString = `{"seq":12,"type":"response","request_seq
 var dataBreakpointInfoResponseStruct = DataBreakpointInfoResponse{
 	Response: *newResponse(12, 13, "dataBreakpointInfo", true),
 	Body: DataBreakpointInfoResponseBody{
-		DataId:      nil,
+		DataId:      NullableString{},
 		Description: "some description",
 	},
 }
@@ -567,7 +570,7 @@ var modulesResponseStruct = ModulesResponse{
 	Response: *newResponse(30, 31, "modules", true),
 	Body: ModulesResponseBody{
 		TotalModules: 2,
-		Modules:      []Module{{Id: 1.0, Name: "one"}},
+		Modules:      []Module{{Id: IntOrStringFromInt(1), Name: "one"}},
 	},
 }
 
@@ -815,7 +818,7 @@ var breakpointEventStruct = BreakpointEvent{
 var moduleEventString = `{"seq":9,"type":"event","event":"module","body":{"reason":"removed","module":{"id":"id"}}}`
 var moduleEventStruct = ModuleEvent{
 	Event: *newEvent(9, "module"),
-	Body:  ModuleEventBody{Reason: "removed", Module: Module{Id: "id"}},
+	Body:  ModuleEventBody{Reason: "removed", Module: Module{Id: IntOrStringFromString("id")}},
 }
 
 var loadedSourceEventString = `{"seq":10,"type":"event","event":"loadedSource","body":{"reason":"changed","source":{"name":"hello.go","path":"/Users/foo/go/src/hello/hello.go"}}}`
@@ -1050,6 +1053,92 @@ func TestDecodeProtocolMessage_Custom(t *testing.T) {
 	}
 }
 
+func TestDecodeProtocolMessage_GenericFallback(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantMsg Message
+	}{
+		{
+			`{"seq": 3, "type": "request", "command": "vendorReq", "arguments": {"b": 1, "a": "<&>"}}`,
+			&GenericRequest{Request: *newRequest(3, "vendorReq"), Arguments: json.RawMessage(`{"b": 1, "a": "<&>"}`)},
+		},
+		{
+			`{"seq":4,"type":"response","request_seq":3,"command":"vendorReq","success":true}`,
+			&GenericResponse{Response: *newResponse(4, 3, "vendorReq", true)},
+		},
+		{
+			`{"seq":5,"type":"event","event":"vendorEvt","body":[1,2]}`,
+			&GenericEvent{Event: *newEvent(5, "vendorEvt"), Body: json.RawMessage(`[1,2]`)},
+		},
+	}
+
+	codec := NewCodec()
+	codec.UseGenericFallback()
+	for _, test := range tests {
+		t.Run(test.data, func(t *testing.T) {
+			msg, err := codec.DecodeMessage([]byte(test.data))
+			if err != nil {
+				t.Fatalf("codec.DecodeMessage() failed with %v", err)
+			}
+			if got := withoutSnapshot(msg); !reflect.DeepEqual(got, test.wantMsg) {
+				t.Errorf("got message %#v, want %#v", got, test.wantMsg)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteProtocolMessage(&buf, msg); err != nil {
+				t.Fatal(err)
+			}
+			content, err := ReadBaseMessage(bufio.NewReader(&buf))
+			if err != nil || string(content) != test.data {
+				t.Errorf("got re-encoded %s, err=%v, want %s", content, err, test.data)
+			}
+
+			// Once modified, the message is encoded from its fields.
+			setSeq(msg, 10)
+			buf.Reset()
+			if err := WriteProtocolMessage(&buf, msg); err != nil {
+				t.Fatal(err)
+			}
+			content, err = ReadBaseMessage(bufio.NewReader(&buf))
+			if err != nil || string(content) == test.data {
+				t.Fatalf("got re-encoded %s, err=%v, want a new encoding", content, err)
+			}
+			if _, err := DecodeProtocolMessage(content); err == nil {
+				t.Error("got nil error decoding with the default codec, want unsupported")
+			}
+			decoded, err := codec.DecodeMessage(content)
+			if err != nil || decoded.GetSeq() != 10 {
+				t.Errorf("got %#v, err=%v, want message with seq 10", decoded, err)
+			}
+		})
+	}
+
+	// Unknown message types are still rejected.
+	if _, err := codec.DecodeMessage([]byte(`{"seq":1,"type":"foo"}`)); err == nil {
+		t.Error("got nil error for unknown type, want error")
+	}
+}
+
+// withoutSnapshot returns a copy of the generic message m without the
+// record of its encoding.
+func withoutSnapshot(m Message) Message {
+	switch m := m.(type) {
+	case *GenericRequest:
+		c := *m
+		c.decoded = snapshot[Request]{}
+		return &c
+	case *GenericResponse:
+		c := *m
+		c.decoded = snapshot[Response]{}
+		return &c
+	case *GenericEvent:
+		c := *m
+		c.decoded = snapshot[Event]{}
+		return &c
+	}
+	return m
+}
+
 // newRequest builds a Request struct with the specified fields.
 func newRequest(seq int, command string) *Request {
 	return &Request{
@@ -1084,3 +1173,108 @@ func newResponse(seq int, requestSeq int, command string, success bool) *Respons
 		Success:    success,
 	}
 }
+
+func TestDecodeProtocolMessage_Strict(t *testing.T) {
+	tests := []struct {
+		data        string
+		wantUnknown []string
+		wantMissing []string
+	}{
+		{
+			data: `{"seq":1,"type":"request","command":"continue","arguments":{"threadId":1}}`,
+		},
+		{
+			data:        `{"seq":1,"type":"request","command":"continue","arguments":{"threadID":1}}`,
+			wantUnknown: []string{"arguments.threadID"},
+			wantMissing: []string{"arguments.threadId"},
+		},
+		{
+			data:        `{"seq":1,"type":"request","command":"setBreakpoints","arguments":{"breakpoints":[{"line":3}]}}`,
+			wantMissing: []string{"arguments.source"},
+		},
+		{
+			data:        `{"seq":1,"type":"request","command":"launch"}`,
+			wantMissing: []string{"arguments"},
+		},
+		{
+			// The arguments of launch are specific to each debug adapter.
+			data: `{"seq":1,"type":"request","command":"launch","arguments":{"program":"a.out"}}`,
+		},
+		{
+			data:        `{"seq":2,"type":"response","request_seq":1,"command":"setBreakpoints","success":true,"body":{"breakpoints":[{"verified":true,"line":3},{"verifed":true,"lines":4}]}}`,
+			wantUnknown: []string{"body.breakpoints[1].lines", "body.breakpoints[1].verifed"},
+			wantMissing: []string{"body.breakpoints[1].verified"},
+		},
+		{
+			data:        `{"seq":2,"type":"response","command":"next","success":false,"message":"busy"}`,
+			wantMissing: []string{"body", "request_seq"},
+		},
+		{
+			data:        `{"seq":3,"type":"event","event":"stopped","body":{"threadId":1}}`,
+			wantMissing: []string{"body.reason"},
+		},
+		{
+			data:        `{"type":"event","event":"terminated","extra":null}`,
+			wantUnknown: []string{"extra"},
+			wantMissing: []string{"seq"},
+		},
+	}
+
+	codec := NewCodec()
+	codec.UseStrictDecoding()
+	for _, test := range tests {
+		t.Run(test.data, func(t *testing.T) {
+			lenient, err := DecodeProtocolMessage([]byte(test.data))
+			if err != nil {
+				t.Fatalf("DecodeProtocolMessage() failed with %v", err)
+			}
+			msg, err := codec.DecodeMessage([]byte(test.data))
+			if !reflect.DeepEqual(msg, lenient) {
+				t.Errorf("got message %#v, want %#v", msg, lenient)
+			}
+			if test.wantUnknown == nil && test.wantMissing == nil {
+				if err != nil {
+					t.Errorf("got err=%v, want nil", err)
+				}
+				return
+			}
+			var se *StrictDecodeError
+			if !errors.As(err, &se) {
+				t.Fatalf("got err=%v, want *StrictDecodeError", err)
+			}
+			if !reflect.DeepEqual(se.Unknown, test.wantUnknown) || !reflect.DeepEqual(se.Missing, test.wantMissing) {
+				t.Errorf("got unknown %q, missing %q, want unknown %q, missing %q", se.Unknown, se.Missing, test.wantUnknown, test.wantMissing)
+			}
+		})
+	}
+}
+
+func TestNewResponse(t *testing.T) {
+	codec := NewCodec()
+	codec.RegisterRequest("customReq", func() Message { return new(customRequest) }, func() Message { return new(customResponse) })
+
+	tests := []struct {
+		codec *Codec
+		req   RequestMessage
+		want  ResponseMessage
+	}{
+		{defaultCodec, &NextRequest{Request: *newRequest(3, "next")}, &NextResponse{Response: *newResponse(0, 3, "next", true)}},
+		{defaultCodec, &Request{ProtocolMessage: ProtocolMessage{Seq: 4}, Command: "customReq"}, &GenericResponse{Response: *newResponse(0, 4, "customReq", true)}},
+		{codec, &Request{ProtocolMessage: ProtocolMessage{Seq: 4}, Command: "customReq"}, &customResponse{Response: *newResponse(0, 4, "customReq", true)}},
+	}
+	for _, test := range tests {
+		if got := test.codec.NewResponse(test.req); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("got %#v, want %#v", got, test.want)
+		}
+	}
+	if got, ok := NewResponse(&ThreadsRequest{Request: *newRequest(5, "threads")}).(*ThreadsResponse); !ok || got.RequestSeq != 5 {
+		t.Errorf("got %#v, want *ThreadsResponse to seq 5", got)
+	}
+
+	er := NewErrorResponse(&StackTraceRequest{Request: *newRequest(9, "stackTrace")}, "Unable to produce stack trace: \"{e}\"",
+		&ErrorMessage{Id: 2004, Format: "Unable to produce stack trace: \"{e}\"", Variables: map[string]string{"e": "Unknown goroutine 1"}, ShowUser: true})
+	er.Seq = 11
+	if !reflect.DeepEqual(er, &errorResponseStruct) {
+		t.Errorf("got %#v, want %#v", er, &errorResponseStruct)
+	}
+}
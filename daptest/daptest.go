@@ -0,0 +1,315 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package daptest provides a fake debug adapter for testing DAP clients.
+//
+// The adapter runs in the test process and talks to the client over an
+// in-memory connection, so tests need neither ports nor sleeps. Tests
+// program how it replies to each command, and check the requests it
+// received:
+//
+//	a := daptest.NewAdapter(t)
+//	a.On(dap.CommandInitialize).Reply(&dap.InitializeResponse{}).Then(dap.NewInitializedEvent())
+//	a.On(dap.CommandStackTrace).Reply(&dap.StackTraceResponse{
+//		Body: dap.StackTraceResponseBody{StackFrames: frames},
+//	})
+//	runClient(a.Conn())
+//
+// Requests that no expectation matches are answered with an ErrorResponse
+// and fail the test, and so do replies of the wrong type. Expectations that
+// are not met once the test ends fail the test as well.
+package daptest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/google/go-dap"
+)
+
+// Adapter is a fake debug adapter that replies to requests as programmed
+// by its expectations.
+type Adapter struct {
+	t    testing.TB
+	s    *dap.Session
+	conn net.Conn
+	done chan struct{}
+
+	mu           sync.Mutex
+	client       *dap.Client
+	expectations []*Expectation
+	received     []dap.RequestMessage
+}
+
+// NewAdapter starts an adapter that serves the client end of an in-memory
+// connection, returned by Conn. The adapter stops when the test ends, and
+// then reports the expectations that were not met.
+func NewAdapter(t testing.TB) *Adapter {
+	clientConn, adapterConn := net.Pipe()
+	a := &Adapter{
+		t:    t,
+		s:    dap.NewSession(adapterConn),
+		conn: clientConn,
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(a.done)
+		a.s.Serve(dap.HandlerFunc(a.handle))
+	}()
+	t.Cleanup(a.close)
+	return a
+}
+
+// Conn returns the client end of the connection, for testing clients that
+// speak DAP over an io.ReadWriteCloser. It must not be used along with
+// Client.
+func (a *Adapter) Conn() net.Conn {
+	return a.conn
+}
+
+// Client returns a client on the client end of the connection, which is
+// created on the first call.
+func (a *Adapter) Client() *dap.Client {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.client == nil {
+		a.client = dap.NewClient(a.conn)
+	}
+	return a.client
+}
+
+// Session returns the session of the adapter, which can be used to send
+// reverse requests to the client.
+func (a *Adapter) Session() *dap.Session {
+	return a.s
+}
+
+// Send sends m, typically an event, to the client. The Seq of m is
+// assigned by the adapter.
+func (a *Adapter) Send(m dap.Message) {
+	a.t.Helper()
+	if err := a.s.Send(m); err != nil {
+		a.t.Errorf("daptest: sending %T: %v", m, err)
+	}
+}
+
+// On adds an expectation that a request for command arrives, once unless
+// changed with Times or AnyTimes. By default, the adapter replies to it
+// with an empty successful response. Expectations for the same command
+// are matched in the order they were added, each until it has been met
+// as many times as it expects.
+func (a *Adapter) On(command string) *Expectation {
+	e := &Expectation{command: command, min: 1, max: 1, done: make(chan struct{})}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.expectations = append(a.expectations, e)
+	return e
+}
+
+// Requests returns the requests received so far, in the order they
+// arrived, including those that no expectation matched.
+func (a *Adapter) Requests() []dap.RequestMessage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]dap.RequestMessage(nil), a.received...)
+}
+
+// handle replies to req as programmed by the first expectation that
+// matches it.
+func (a *Adapter) handle(ctx context.Context, req dap.RequestMessage) (dap.ResponseMessage, error) {
+	command := req.GetRequest().Command
+	a.mu.Lock()
+	a.received = append(a.received, req)
+	var e *Expectation
+	for _, x := range a.expectations {
+		if x.command == command && (x.max < 0 || x.count < x.max) {
+			e = x
+			break
+		}
+	}
+	if e != nil {
+		e.count++
+		if e.count == e.min {
+			e.met()
+		}
+	}
+	a.mu.Unlock()
+
+	if e == nil {
+		a.t.Errorf("daptest: unexpected %s request (seq: %d)", command, req.GetSeq())
+		return nil, fmt.Errorf("daptest: unexpected %s request", command)
+	}
+	if len(e.events) > 0 {
+		a.s.AfterResponse(ctx, func() {
+			for _, ev := range e.events {
+				a.Send(copyMessage(ev))
+			}
+		})
+	}
+	resp, err := e.reply(req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	if got, want := reflect.TypeOf(resp), responseTypes[reflect.TypeOf(req)]; got != want {
+		a.t.Errorf("daptest: %v is not a response to a %s request", got, command)
+		return nil, fmt.Errorf("daptest: %v is not a response to a %s request", got, command)
+	}
+	return resp, nil
+}
+
+// responseTypes maps the type of each request to the type of its
+// response, as given by the methods of dap.Handler.
+var responseTypes = func() map[reflect.Type]reflect.Type {
+	types := make(map[reflect.Type]reflect.Type)
+	h := reflect.TypeOf((*dap.Handler)(nil)).Elem()
+	for i := 0; i < h.NumMethod(); i++ {
+		m := h.Method(i).Type
+		types[m.In(1)] = m.Out(0)
+	}
+	return types
+}()
+
+// close stops the adapter and reports the expectations that were not met.
+func (a *Adapter) close() {
+	a.t.Helper()
+	a.mu.Lock()
+	client := a.client
+	a.mu.Unlock()
+	if client != nil {
+		client.Close()
+	}
+	a.conn.Close()
+	<-a.done
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, e := range a.expectations {
+		if e.count < e.min {
+			a.t.Errorf("daptest: got %d %s requests, want %s", e.count, e.command, e.want())
+		}
+	}
+}
+
+// copyMessage returns a shallow copy of m, so that the copy can be sent
+// while m is reused for other requests.
+func copyMessage[M dap.Message](m M) M {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Pointer {
+		return m
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	return c.Interface().(M)
+}
+
+// Expectation describes requests for a command that the adapter expects,
+// and how it replies to them. Its methods return the expectation, so that
+// calls can be chained. They must be called before the requests arrive.
+type Expectation struct {
+	command  string
+	min, max int // max is negative if unlimited
+	count    int
+	done     chan struct{}
+	doneOnce sync.Once
+
+	resp   dap.ResponseMessage
+	err    error
+	do     func(req dap.RequestMessage) (dap.ResponseMessage, error)
+	events []dap.Message
+}
+
+// Reply makes the adapter reply with resp, whose Seq, RequestSeq, Command
+// and Success fields are filled in by the adapter. resp must match the
+// type of the request, such as a *dap.StackTraceResponse for a
+// *dap.StackTraceRequest, or the test fails.
+func (e *Expectation) Reply(resp dap.ResponseMessage) *Expectation {
+	e.resp = resp
+	return e
+}
+
+// ReplyError makes the adapter reply with an ErrorResponse for err, which
+// carries the ErrorMessage of err if it is or wraps a *dap.Error.
+func (e *Expectation) ReplyError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Do makes the adapter reply with the response or error returned by f,
+// which is called with each matching request. It takes precedence over
+// Reply and ReplyError.
+func (e *Expectation) Do(f func(req dap.RequestMessage) (dap.ResponseMessage, error)) *Expectation {
+	e.do = f
+	return e
+}
+
+// Then makes the adapter send events after the response, such as the
+// initialized event after the response to initialize.
+func (e *Expectation) Then(events ...dap.Message) *Expectation {
+	e.events = append(e.events, events...)
+	return e
+}
+
+// reply returns the response or error with which the adapter replies to
+// req.
+func (e *Expectation) reply(req dap.RequestMessage) (dap.ResponseMessage, error) {
+	if e.do != nil {
+		return e.do(req)
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	if e.resp != nil {
+		return copyMessage(e.resp), nil
+	}
+	return nil, nil
+}
+
+// Times makes the expectation match exactly n requests.
+func (e *Expectation) Times(n int) *Expectation {
+	e.min, e.max = n, n
+	return e
+}
+
+// AnyTimes makes the expectation match any number of requests, including
+// none.
+func (e *Expectation) AnyTimes() *Expectation {
+	e.min, e.max = 0, -1
+	return e
+}
+
+// Done returns a channel that is closed once the expectation is met, so
+// that tests can wait for requests that the client sends on its own.
+func (e *Expectation) Done() <-chan struct{} {
+	if e.min == 0 {
+		// Met from the start.
+		e.met()
+	}
+	return e.done
+}
+
+// met closes the channel returned by Done, once.
+func (e *Expectation) met() {
+	e.doneOnce.Do(func() { close(e.done) })
+}
+
+// want describes how many requests e expects.
+func (e *Expectation) want() string {
+	if e.max < 0 {
+		return fmt.Sprintf("at least %d", e.min)
+	}
+	return fmt.Sprint(e.min)
+}
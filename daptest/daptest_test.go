@@ -0,0 +1,177 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daptest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-dap"
+)
+
+// recorder is a testing.TB that records errors instead of failing the
+// test, and runs its cleanups when finish is called.
+type recorder struct {
+	testing.TB
+	mu       sync.Mutex
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+// finish runs the cleanups and returns the recorded errors.
+func (r *recorder) finish() []string {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.errors
+}
+
+func TestAdapter(t *testing.T) {
+	a := NewAdapter(t)
+	frames := []dap.StackFrame{{Id: 1000, Name: "main.main", Line: 5}}
+	a.On(dap.CommandInitialize).Reply(&dap.InitializeResponse{Body: dap.Capabilities{SupportsConfigurationDoneRequest: true}}).Then(dap.NewInitializedEvent())
+	a.On(dap.CommandStackTrace).Reply(&dap.StackTraceResponse{Body: dap.StackTraceResponseBody{StackFrames: frames, TotalFrames: 1}}).Times(2)
+	a.On(dap.CommandStackTrace).ReplyError(&dap.Error{Id: 2004, Format: "no frames"})
+	a.On(dap.CommandEvaluate).Do(func(req dap.RequestMessage) (dap.ResponseMessage, error) {
+		expr := req.(*dap.EvaluateRequest).Arguments.Expression
+		return &dap.EvaluateResponse{Body: dap.EvaluateResponseBody{Result: strings.ToUpper(expr)}}, nil
+	}).AnyTimes()
+	disconnect := a.On(dap.CommandDisconnect)
+
+	c := a.Client()
+	resp, err := c.Call(dap.NewInitializeRequest(dap.InitializeRequestArguments{AdapterID: "go"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.(*dap.InitializeResponse).Body.SupportsConfigurationDoneRequest {
+		t.Errorf("got %#v, want the programmed capabilities", resp)
+	}
+	if e, ok := (<-c.Events()).(*dap.InitializedEvent); !ok || e.Seq != 2 {
+		t.Errorf("got %#v, want initialized event with seq 2", e)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := c.Call(dap.NewStackTraceRequest(dap.StackTraceArguments{ThreadId: 1}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.(*dap.StackTraceResponse).Body.StackFrames; !reflect.DeepEqual(got, frames) {
+			t.Errorf("got frames %#v, want %#v", got, frames)
+		}
+	}
+	if _, err := c.Call(dap.NewStackTraceRequest(dap.StackTraceArguments{ThreadId: 1})); !errors.Is(err, &dap.Error{Id: 2004}) {
+		t.Errorf("got err=%v, want error 2004", err)
+	}
+
+	for _, expr := range []string{"a", "b"} {
+		resp, err := c.Call(dap.NewEvaluateRequest(dap.EvaluateArguments{Expression: expr}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := resp.(*dap.EvaluateResponse).Body.Result, strings.ToUpper(expr); got != want {
+			t.Errorf("got result %q, want %q", got, want)
+		}
+	}
+
+	a.Send(dap.NewStoppedEvent(dap.StoppedEventBody{Reason: dap.StoppedEventBodyReasonPause, ThreadId: 1}))
+	if e, ok := (<-c.Events()).(*dap.StoppedEvent); !ok || e.Body.Reason != dap.StoppedEventBodyReasonPause {
+		t.Errorf("got %#v, want stopped event", e)
+	}
+
+	// The client does not wait for the response to disconnect.
+	if err := c.Session().Send(dap.NewDisconnectRequest(nil)); err != nil {
+		t.Fatal(err)
+	}
+	<-disconnect.Done()
+
+	var commands []string
+	for _, req := range a.Requests() {
+		commands = append(commands, req.GetRequest().Command)
+	}
+	want := []string{"initialize", "stackTrace", "stackTrace", "stackTrace", "evaluate", "evaluate", "disconnect"}
+	if !reflect.DeepEqual(commands, want) {
+		t.Errorf("got requests %v, want %v", commands, want)
+	}
+}
+
+func TestAdapterFailures(t *testing.T) {
+	r := &recorder{TB: t}
+	a := NewAdapter(r)
+	a.On(dap.CommandThreads).Reply(&dap.ThreadsResponse{})
+	a.On(dap.CommandPause).Times(2)
+	a.On(dap.CommandModules).AnyTimes()
+	a.On(dap.CommandScopes).Reply(&dap.ThreadsResponse{})
+	a.On(dap.CommandSource).Do(func(req dap.RequestMessage) (dap.ResponseMessage, error) {
+		return &dap.ScopesResponse{}, nil
+	})
+
+	c := a.Client()
+	if _, err := c.Call(dap.NewPauseRequest(dap.PauseArguments{ThreadId: 1})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Call(dap.NewStepBackRequest(dap.StepBackArguments{ThreadId: 1})); !errors.As(err, new(*dap.ResponseError)) {
+		t.Errorf("got err=%v for an unexpected request, want *dap.ResponseError", err)
+	}
+	if _, err := c.Call(dap.NewScopesRequest(dap.ScopesArguments{FrameId: 1})); !errors.As(err, new(*dap.ResponseError)) {
+		t.Errorf("got err=%v for a mismatched reply, want *dap.ResponseError", err)
+	}
+	if _, err := c.Call(dap.NewSourceRequest(dap.SourceArguments{SourceReference: 1})); !errors.As(err, new(*dap.ResponseError)) {
+		t.Errorf("got err=%v for a mismatched reply, want *dap.ResponseError", err)
+	}
+
+	got := r.finish()
+	want := []string{
+		"daptest: unexpected stepBack request (seq: 2)",
+		"daptest: *dap.ThreadsResponse is not a response to a scopes request",
+		"daptest: *dap.ScopesResponse is not a response to a source request",
+		"daptest: got 0 threads requests, want 1",
+		"daptest: got 1 pause requests, want 2",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got errors %q, want %q", got, want)
+	}
+}
+
+func TestExpectationDone(t *testing.T) {
+	a := NewAdapter(t)
+	e := a.On(dap.CommandModules).AnyTimes()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-e.Done()
+		}()
+	}
+	wg.Wait()
+}
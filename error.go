@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains a Go error type that corresponds to the ErrorMessage
+// of an ErrorResponse.
+
+package dap
+
+import "strings"
+
+// Error is a Go error that carries the fields of an ErrorMessage, the
+// structured error of an ErrorResponse. A Session answers a request whose
+// handler returns an *Error, possibly wrapped, with an ErrorResponse that
+// contains the corresponding ErrorMessage. Conversely, an ErrorResponse
+// received by Session.Call or Client.Call can be retrieved as an *Error
+// with errors.As.
+type Error struct {
+	// Id is the unique identifier of the error.
+	Id int
+	// Format is the message of the error, in which {name} placeholders are
+	// replaced by the value of Variables[name].
+	Format        string
+	Variables     map[string]string
+	ShowUser      bool
+	SendTelemetry bool
+	Url           string
+	UrlLabel      string
+	// Err is the underlying error, if any, which is not sent.
+	Err error
+}
+
+// ErrorFromResponse returns the error described by er. If er has no
+// ErrorMessage, the Format of the error is the message of the response.
+func ErrorFromResponse(er *ErrorResponse) *Error {
+	m := er.Body.Error
+	if m == nil {
+		return &Error{Format: er.Message}
+	}
+	return &Error{
+		Id:            m.Id,
+		Format:        m.Format,
+		Variables:     m.Variables,
+		ShowUser:      m.ShowUser,
+		SendTelemetry: m.SendTelemetry,
+		Url:           m.Url,
+		UrlLabel:      m.UrlLabel,
+	}
+}
+
+// Error returns Format with its placeholders expanded. If Format is empty,
+// it returns the message of Err.
+func (e *Error) Error() string {
+	if e.Format == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return ExpandFormat(e.Format, e.Variables)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same non-zero Id, so that
+// errors.Is can match errors by identifier.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Id != 0 && t.Id == e.Id
+}
+
+// ErrorMessage returns the ErrorMessage that describes e in an
+// ErrorResponse. If Format is empty, the message of Err is the format.
+func (e *Error) ErrorMessage() *ErrorMessage {
+	format := e.Format
+	if format == "" && e.Err != nil {
+		format = e.Err.Error()
+	}
+	return &ErrorMessage{
+		Id:            e.Id,
+		Format:        format,
+		Variables:     e.Variables,
+		ShowUser:      e.ShowUser,
+		SendTelemetry: e.SendTelemetry,
+		Url:           e.Url,
+		UrlLabel:      e.UrlLabel,
+	}
+}
+
+// ExpandFormat returns format with every {name} placeholder replaced by
+// variables[name]. Placeholders without a variable are left unchanged.
+func ExpandFormat(format string, variables map[string]string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(format, '{')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(format[i:], '}')
+		if j < 0 {
+			break
+		}
+		j += i
+		b.WriteString(format[:i])
+		if v, ok := variables[format[i+1:j]]; ok {
+			b.WriteString(v)
+			format = format[j+1:]
+		} else {
+			// Keep the brace, as the placeholder may start later, as in "{{a}".
+			b.WriteByte('{')
+			format = format[i+1:]
+		}
+	}
+	b.WriteString(format)
+	return b.String()
+}
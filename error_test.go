@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+func TestExpandFormat(t *testing.T) {
+	vars := map[string]string{"e": "Unknown goroutine 1", "n": "3", "_x": "y"}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", ""},
+		{"no placeholders", "no placeholders"},
+		{"Unable to produce stack trace: \"{e}\"", "Unable to produce stack trace: \"Unknown goroutine 1\""},
+		{"{n}{n}", "33"},
+		{"{missing} and {n}", "{missing} and 3"},
+		{"{{n}}", "{3}"},
+		{"{_x}", "y"},
+		{"unterminated {n", "unterminated {n"},
+		{"}{n}", "}3"},
+	}
+	for _, test := range tests {
+		if got := ExpandFormat(test.format, vars); got != test.want {
+			t.Errorf("ExpandFormat(%q) = %q, want %q", test.format, got, test.want)
+		}
+	}
+}
+
+func TestError(t *testing.T) {
+	e := &Error{Id: 2004, Format: "cannot open {path}", Variables: map[string]string{"path": "a.go"}, ShowUser: true, Err: fs.ErrNotExist}
+	err := fmt.Errorf("loading source: %w", e)
+	if got, want := err.Error(), "loading source: cannot open a.go"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Error("errors.Is(err, fs.ErrNotExist) = false, want true")
+	}
+	if !errors.Is(err, &Error{Id: 2004}) || errors.Is(err, &Error{Id: 2005}) || errors.Is(&Error{}, &Error{}) {
+		t.Error("errors.Is does not match errors by Id")
+	}
+	if got := (&Error{Err: fs.ErrNotExist}).Error(); got != fs.ErrNotExist.Error() {
+		t.Errorf("got %q, want the message of the wrapped error", got)
+	}
+
+	er := NewErrorResponse(&SourceRequest{Request: *newRequest(1, "source")}, e.Error(), e.ErrorMessage())
+	want := *e
+	want.Err = nil
+	if got := ErrorFromResponse(er); !reflect.DeepEqual(got, &want) {
+		t.Errorf("got %#v, want %#v", got, &want)
+	}
+	er.Body.Error = nil
+	if got := ErrorFromResponse(er); got.Format != "cannot open a.go" || got.Id != 0 {
+		t.Errorf("got %#v, want the message of the response", got)
+	}
+
+	// Without a Format, the wrapped error provides the message.
+	e = &Error{Id: 2005, Err: fs.ErrNotExist}
+	if got := e.ErrorMessage(); got.Id != 2005 || got.Format != fs.ErrNotExist.Error() {
+		t.Errorf("got %#v, want the message of the wrapped error as the format", got)
+	}
+}
+
+type errorHandler struct {
+	UnimplementedHandler
+	err error
+}
+
+func (h errorHandler) OnSourceRequest(ctx context.Context, req *SourceRequest) (*SourceResponse, error) {
+	return nil, h.err
+}
+
+func TestSessionError(t *testing.T) {
+	handlerErr := &Error{Id: 3001, Format: "no source for {ref}", Variables: map[string]string{"ref": "5"}, Url: "https://example.com", Err: fs.ErrNotExist}
+	c := startSession(t, func(*Session) Handler { return errorHandler{err: fmt.Errorf("source: %w", handlerErr)} })
+
+	_, err := c.Call(&SourceRequest{Arguments: SourceArguments{SourceReference: 5}})
+	var re *ResponseError
+	if !errors.As(err, &re) || re.Response.Message != "source: no source for 5" {
+		t.Fatalf("got err=%v, want *ResponseError", err)
+	}
+	var de *Error
+	if !errors.As(err, &de) || de.Id != 3001 || de.Url != "https://example.com" || de.Error() != "no source for 5" {
+		t.Errorf("got %#v, want *Error 3001", de)
+	}
+	if !errors.Is(err, &Error{Id: 3001}) {
+		t.Error("errors.Is(err, &Error{Id: 3001}) = false, want true")
+	}
+	// The underlying error stays on the side of the handler.
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Error("errors.Is(err, fs.ErrNotExist) = true, want false")
+	}
+}
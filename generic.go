@@ -0,0 +1,163 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains generic message types, into which a Codec can decode
+// requests, responses and events it does not know, such as vendor
+// extensions, so that they can be passed through unchanged.
+
+package dap
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// GenericRequest is a request for a command unknown to the Codec that
+// decoded it. See Codec.UseGenericFallback.
+//
+// As long as it is not modified, a decoded GenericRequest is encoded by
+// WriteProtocolMessage, Encoder and SeqWriter exactly as it was received.
+type GenericRequest struct {
+	Request
+
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+
+	decoded snapshot[Request]
+}
+
+func (r *GenericRequest) UnmarshalJSON(data []byte) error {
+	type genericRequest GenericRequest
+	if err := json.Unmarshal(data, (*genericRequest)(r)); err != nil {
+		return err
+	}
+	r.decoded = newSnapshot(data, r.Request, r.Arguments)
+	return nil
+}
+
+func (r *GenericRequest) MarshalJSON() ([]byte, error) {
+	if b := r.rawJSON(); b != nil {
+		return b, nil
+	}
+	type genericRequest GenericRequest
+	return json.Marshal((*genericRequest)(r))
+}
+
+func (r *GenericRequest) rawJSON() []byte {
+	return r.decoded.rawJSON(r.Request, r.Arguments)
+}
+
+// GenericResponse is a successful response for a command unknown to the
+// Codec that decoded it. See Codec.UseGenericFallback.
+//
+// As long as it is not modified, a decoded GenericResponse is encoded by
+// WriteProtocolMessage, Encoder and SeqWriter exactly as it was received.
+type GenericResponse struct {
+	Response
+
+	Body json.RawMessage `json:"body,omitempty"`
+
+	decoded snapshot[Response]
+}
+
+func (r *GenericResponse) UnmarshalJSON(data []byte) error {
+	type genericResponse GenericResponse
+	if err := json.Unmarshal(data, (*genericResponse)(r)); err != nil {
+		return err
+	}
+	r.decoded = newSnapshot(data, r.Response, r.Body)
+	return nil
+}
+
+func (r *GenericResponse) MarshalJSON() ([]byte, error) {
+	if b := r.rawJSON(); b != nil {
+		return b, nil
+	}
+	type genericResponse GenericResponse
+	return json.Marshal((*genericResponse)(r))
+}
+
+func (r *GenericResponse) rawJSON() []byte {
+	return r.decoded.rawJSON(r.Response, r.Body)
+}
+
+// GenericEvent is an event unknown to the Codec that decoded it. See
+// Codec.UseGenericFallback.
+//
+// As long as it is not modified, a decoded GenericEvent is encoded by
+// WriteProtocolMessage, Encoder and SeqWriter exactly as it was received.
+type GenericEvent struct {
+	Event
+
+	Body json.RawMessage `json:"body,omitempty"`
+
+	decoded snapshot[Event]
+}
+
+func (e *GenericEvent) UnmarshalJSON(data []byte) error {
+	type genericEvent GenericEvent
+	if err := json.Unmarshal(data, (*genericEvent)(e)); err != nil {
+		return err
+	}
+	e.decoded = newSnapshot(data, e.Event, e.Body)
+	return nil
+}
+
+func (e *GenericEvent) MarshalJSON() ([]byte, error) {
+	if b := e.rawJSON(); b != nil {
+		return b, nil
+	}
+	type genericEvent GenericEvent
+	return json.Marshal((*genericEvent)(e))
+}
+
+func (e *GenericEvent) rawJSON() []byte {
+	return e.decoded.rawJSON(e.Event, e.Body)
+}
+
+// snapshot records the encoding of a generic message along with the
+// header and payload decoded from it, to tell whether the message was
+// modified since.
+type snapshot[H comparable] struct {
+	raw     []byte
+	header  H
+	payload []byte
+}
+
+func newSnapshot[H comparable](raw []byte, header H, payload json.RawMessage) snapshot[H] {
+	return snapshot[H]{
+		raw:     append([]byte(nil), raw...),
+		header:  header,
+		payload: append([]byte(nil), payload...),
+	}
+}
+
+// rawJSON returns the encoding the message was decoded from, or nil if it
+// was not decoded or its header or payload changed since.
+func (s *snapshot[H]) rawJSON(header H, payload json.RawMessage) []byte {
+	if s.raw == nil || header != s.header || !bytes.Equal(payload, s.payload) {
+		return nil
+	}
+	return s.raw
+}
+
+// marshalMessage encodes message, reproducing the exact encoding of
+// unmodified generic messages, which json.Marshal would compact.
+func marshalMessage(message Message) ([]byte, error) {
+	if m, ok := message.(interface{ rawJSON() []byte }); ok {
+		if b := m.rawJSON(); b != nil {
+			return b, nil
+		}
+	}
+	return json.Marshal(message)
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file defines helpers for the Handler interface implemented by debug
+// adapters served by a Session. The interface, with one method per request
+// of the specification, and the dispatching of requests to its methods are
+// generated along with the request types in schematypes.go.
+
+package dap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedRequest is returned (wrapped) by the methods of
+// UnimplementedHandler.
+var ErrUnsupportedRequest = errors.New("request is not supported")
+
+// UnimplementedHandler implements Handler by replying to every request with
+// an ErrorResponse saying that the request is not supported, except for
+// cancel requests, which a Session carries out before dispatching them and
+// which are therefore acknowledged. It is meant to be embedded in Handler
+// implementations.
+type UnimplementedHandler struct{}
+
+// HandlerFunc adapts a function that handles requests of any type to the
+// Handler interface. The function returns the response to send back, which
+// must match the type of the request, such as a *StackTraceResponse for a
+// *StackTraceRequest, or nil for an empty successful response.
+type HandlerFunc func(ctx context.Context, req RequestMessage) (ResponseMessage, error)
+
+// callHandlerFunc calls f with req and checks that the response it returns,
+// if any, is a *R.
+func callHandlerFunc[R any, PR interface {
+	*R
+	ResponseMessage
+}](ctx context.Context, f HandlerFunc, req RequestMessage) (PR, error) {
+	resp, err := f(ctx, req)
+	if err != nil || resp == nil {
+		return nil, err
+	}
+	r, ok := resp.(PR)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a response to a %s request", resp, req.GetRequest().Command)
+	}
+	return r, nil
+}
+
+func unsupported(req RequestMessage) error {
+	return fmt.Errorf("%s %w", req.GetRequest().Command, ErrUnsupportedRequest)
+}
+
+// handle calls f with req and turns a nil response into an empty one, so
+// that a typed nil pointer never ends up in the returned interface.
+func handle[Req RequestMessage, R any, PR interface {
+	*R
+	ResponseMessage
+}](ctx context.Context, req Req, f func(context.Context, Req) (PR, error)) (ResponseMessage, error) {
+	resp, err := f(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		resp = new(R)
+	}
+	return resp, nil
+}
@@ -20,12 +20,17 @@ package dap
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
-	"regexp"
+	"math"
+	"net/textproto"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // BaseProtocolError represents base protocol error, which occurs when the raw
@@ -41,24 +46,51 @@ var (
 	// delimiter \r\n\r\n is encountered.
 	ErrHeaderDelimiterNotCrLfCrLf = &BaseProtocolError{fmt.Sprintf("header delimiter is not %q", crLfcrLf)}
 
-	// ErrHeaderNotContentLength is returned when the parsed header is
-	// not of valid Content-Length format.
-	ErrHeaderNotContentLength = &BaseProtocolError{fmt.Sprintf("header format is not %q", contentLengthHeaderRegex)}
+	// ErrHeaderNotContentLength is returned when the parsed header has no
+	// Content-Length field, its value is not a non-negative integer, or its
+	// first line is not a header field of the form "Name: value".
+	ErrHeaderNotContentLength = &BaseProtocolError{fmt.Sprintf("header has no valid %q field", contentLengthField)}
 
 	// ErrHeaderContentTooLong is returned when the content length specified in
-	// the header is above contentMaxLength.
-	ErrHeaderContentTooLong = &BaseProtocolError{fmt.Sprintf("content length over %v bytes", contentMaxLength)}
+	// the header is above the maximum, DefaultMaxContentLength unless
+	// configured otherwise with a Reader.
+	ErrHeaderContentTooLong = &BaseProtocolError{"content length over maximum"}
+
+	// ErrHeaderTooLong is returned when the header is longer than the maximum,
+	// DefaultMaxHeaderLength unless configured otherwise with a Reader.
+	ErrHeaderTooLong = &BaseProtocolError{"header length over maximum"}
+)
+
+const (
+	// DefaultMaxContentLength is the maximum content length accepted by
+	// ReadBaseMessage and by a Reader with a zero MaxContentLength.
+	DefaultMaxContentLength = 4 * 1024 * 1024
+	// DefaultMaxHeaderLength is the maximum header length, excluding the
+	// \r\n\r\n delimiter, accepted by ReadBaseMessage and by a Reader with a
+	// zero MaxHeaderLength.
+	DefaultMaxHeaderLength = 4 * 1024
 )
 
 const (
 	crLfcrLf               = "\r\n\r\n"
+	contentLengthField     = "Content-Length"
 	contentLengthHeaderFmt = "Content-Length: %d\r\n\r\n"
-	contentMaxLength       = 4 * 1024 * 1024
 )
 
-var (
-	contentLengthHeaderRegex = regexp.MustCompile("^Content-Length: ([0-9]+)$")
-)
+// Header holds the header fields of a base protocol message, keyed by their
+// canonical names, as returned by textproto.CanonicalMIMEHeaderKey.
+type Header map[string]string
+
+// Get returns the value of the field name, which is case-insensitive, or an
+// empty string if there is no such field.
+func (h Header) Get(name string) string {
+	return h[textproto.CanonicalMIMEHeaderKey(name)]
+}
+
+// Set sets the value of the field name, which is case-insensitive.
+func (h Header) Set(name, value string) {
+	h[textproto.CanonicalMIMEHeaderKey(name)] = value
+}
 
 // WriteBaseMessage formats content with Content-Length header and delimiters
 // as per the base protocol and writes the resulting message to w.
@@ -71,62 +103,583 @@ func WriteBaseMessage(w io.Writer, content []byte) error {
 	return err
 }
 
+// WriteBaseMessageWithHeader is like WriteBaseMessage, but writes the fields
+// of header after the Content-Length field, sorted by name. A Content-Length
+// field in header is ignored.
+func WriteBaseMessageWithHeader(w io.Writer, content []byte, header Header) error {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		if name != contentLengthField {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d\r\n", contentLengthField, len(content))
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\r\n", name, header[name])
+	}
+	b.WriteString("\r\n")
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return err
+	}
+	_, err := w.Write(content)
+	return err
+}
+
 // ReadBaseMessage reads one message from r consisting of a Content-Length
 // header and a content part. It parses the header to determine the size of
 // the content part and extracts and returns the actual content of the message.
 // Returns nil bytes on error, which can be one of the standard IO errors or
 // a BaseProtocolError defined in this package.
+//
+// The header and content lengths are limited to DefaultMaxHeaderLength and
+// DefaultMaxContentLength. Use a Reader to configure the limits.
 func ReadBaseMessage(r *bufio.Reader) ([]byte, error) {
-	contentLength, err := readContentLengthHeader(r)
+	return readBaseMessage(r, DefaultMaxHeaderLength, DefaultMaxContentLength, nil, nil)
+}
+
+// ReadBaseMessageWithHeader is like ReadBaseMessage, but also returns the
+// fields of the header, including Content-Length.
+func ReadBaseMessageWithHeader(r *bufio.Reader) ([]byte, Header, error) {
+	header := make(Header)
+	content, err := readBaseMessage(r, DefaultMaxHeaderLength, DefaultMaxContentLength, header, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, header, nil
+}
+
+// readBaseMessage implements ReadBaseMessageWithHeader with the given
+// limits, which are disabled if negative. The fields of the header are
+// stored in header, unless it is nil. The content is read into buf if it
+// has enough capacity, and into a new slice otherwise.
+func readBaseMessage(r *bufio.Reader, maxHeaderLength int, maxContentLength int64, header Header, buf []byte) ([]byte, error) {
+	contentLength, err := readHeader(r, maxHeaderLength, header)
 	if err != nil {
 		return nil, err
 	}
-	if contentLength > contentMaxLength {
+	if maxContentLength >= 0 && contentLength > maxContentLength {
 		return nil, ErrHeaderContentTooLong
 	}
-	content := make([]byte, contentLength)
+	var content []byte
+	if int64(cap(buf)) >= contentLength {
+		content = buf[:contentLength]
+	} else {
+		content = make([]byte, contentLength)
+	}
 	if _, err = io.ReadFull(r, content); err != nil {
 		return nil, err
 	}
 	return content, nil
 }
 
-// readContentLengthHeader looks for the only header field that is supported
-// and required:
-// 		Content-Length: [0-9]+\r\n\r\n
-// Extracts and returns the content length.
-func readContentLengthHeader(r *bufio.Reader) (contentLength int64, err error) {
-	// Look for <some header>\r\n\r\n
-	headerWithCr, err := r.ReadString('\r')
+// readHeader reads the header of a message, made of header fields of the
+// form "Name: value", each followed by \r\n, and an empty line:
+//
+//	Content-Length: 3\r\n
+//	Content-Type: application/vscode-jsonrpc; charset=utf-8\r\n
+//	\r\n
+//
+// Field names are case-insensitive, whitespace around names and values is
+// ignored, and fields may come in any order, but Content-Length is required.
+// Returns the content length and, unless header is nil, stores the fields in
+// header. The header may be at most maxLength bytes long, excluding the
+// delimiter, unless maxLength is negative.
+//
+// The \r ending a line must be followed by \n and either \r\n or another
+// header field. Otherwise, the delimiter is malformed, and the 3 bytes
+// following the \r are consumed, as they were when Content-Length was the
+// only field supported. A first line that is not a header field is reported
+// as ErrHeaderNotContentLength once the whole header is read, so that r is
+// left at the start of the content.
+func readHeader(r *bufio.Reader, maxLength int, header Header) (contentLength int64, err error) {
+	length, lines := 0, 0
+	hasContentLength, malformed := false, false
+	for {
+		limit := -1
+		if maxLength >= 0 {
+			limit = maxLength - length
+			if lines > 0 {
+				limit -= 2 // The \r\n separating this line from the previous one.
+			}
+			if limit < 0 {
+				limit = 0
+			}
+		}
+		line, err := readUntilCr(r, limit)
+		if err != nil {
+			return 0, headerReadError(err, lines)
+		}
+		line = line[:len(line)-1]
+		if lines > 0 {
+			length += 2
+		}
+		length += len(line)
+		name, value, ok := splitField(line)
+		switch {
+		case !ok:
+			malformed = true
+		case strings.EqualFold(string(name), contentLengthField):
+			contentLength, hasContentLength = parseContentLength(value)
+			fallthrough
+		default:
+			if header != nil {
+				header.Set(string(name), string(value))
+			}
+		}
+		// line must not be used from now on: reading more from r may
+		// overwrite it.
+		next, err := r.Peek(3)
+		if err != nil {
+			r.Discard(len(next))
+			if err == io.EOF && len(next) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, headerReadError(err, lines)
+		}
+		if string(next) == "\n\r\n" {
+			r.Discard(3)
+			break
+		}
+		if next[0] != '\n' || !fieldFollows(r, 1) {
+			r.Discard(3)
+			return 0, ErrHeaderDelimiterNotCrLfCrLf
+		}
+		r.Discard(1)
+		lines++
+	}
+	if malformed || !hasContentLength {
+		return 0, ErrHeaderNotContentLength
+	}
+	return contentLength, nil
+}
+
+// splitField splits a header field of the form "Name: value" into its name
+// and value, without surrounding whitespace. ok is false if the field is
+// malformed.
+func splitField(line []byte) (name, value []byte, ok bool) {
+	i := bytes.IndexByte(line, ':')
+	if i < 0 {
+		return nil, nil, false
+	}
+	name, value = bytes.TrimSpace(line[:i]), bytes.TrimSpace(line[i+1:])
+	return name, value, len(name) > 0
+}
+
+// fieldFollows reports whether the data following the first n bytes
+// buffered in r starts with the name of a header field and a colon, without
+// consuming it. It also returns true if the buffer of r fills up before
+// that is known, leaving the field to be checked once read.
+func fieldFollows(r *bufio.Reader, n int) bool {
+	for i := n; ; i++ {
+		b, err := r.Peek(i + 1)
+		if err != nil {
+			return err == bufio.ErrBufferFull
+		}
+		switch b[i] {
+		case ':':
+			return len(bytes.TrimSpace(b[n:i])) > 0
+		case '\r', '\n':
+			return false
+		}
+	}
+}
+
+// headerReadError returns the error to report when reading a header fails
+// with err after the given number of complete lines: io.EOF only if nothing
+// of the header was read, io.ErrUnexpectedEOF otherwise.
+func headerReadError(err error, lines int) error {
+	if err == io.EOF && lines > 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// parseContentLength parses the value of a Content-Length field, which must
+// consist of decimal digits only and fit in an int64.
+func parseContentLength(value []byte) (int64, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+	var n int64
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		d := int64(c - '0')
+		if n > (math.MaxInt64-d)/10 {
+			return 0, false
+		}
+		n = n*10 + d
+	}
+	return n, true
+}
+
+// readUntilCr reads from r until the first '\r' and returns the data read,
+// including the '\r'. It returns ErrHeaderTooLong as soon as more than
+// maxLength bytes precede the '\r', unless maxLength is negative. The
+// returned slice may point into the buffer of r, so it is only valid until
+// the next read.
+func readUntilCr(r *bufio.Reader, maxLength int) ([]byte, error) {
+	var line []byte
+	for first := true; ; first = false {
+		b, err := r.ReadSlice('\r')
+		if first && err != bufio.ErrBufferFull {
+			line = b // The common case, which needs no copy.
+		} else {
+			line = append(line, b...)
+		}
+		n := len(line)
+		if err == nil {
+			n-- // The '\r' does not count.
+		}
+		if maxLength >= 0 && n > maxLength {
+			return nil, ErrHeaderTooLong
+		}
+		if err != bufio.ErrBufferFull {
+			return line, err
+		}
+	}
+}
+
+// Reader reads base protocol messages from an underlying reader, like
+// ReadBaseMessage and ReadProtocolMessage do, but with configurable limits
+// on the size of the messages. The limits can be changed between reads.
+type Reader struct {
+	r *bufio.Reader
+
+	// MaxHeaderLength is the maximum length of the header of a message,
+	// excluding the \r\n\r\n delimiter. If zero, DefaultMaxHeaderLength is
+	// used. If negative, the length is not limited.
+	MaxHeaderLength int
+	// MaxContentLength is the maximum length of the content of a message.
+	// If zero, DefaultMaxContentLength is used. If negative, the length is
+	// not limited.
+	MaxContentLength int64
+
+	// OnFramingError, if not nil, enables recovery from framing errors,
+	// which are reported as *BaseProtocolError, such as a malformed header
+	// or content over the maximum length. Instead of returning such an
+	// error, the reader skips to the next Content-Length header with Resync,
+	// calls OnFramingError with the error and the number of bytes skipped,
+	// and reads the message found there.
+	OnFramingError func(err error, discarded int64)
+}
+
+// NewReader returns a Reader that reads from r with the default limits. If
+// r is a *bufio.Reader, it is used directly.
+func NewReader(r io.Reader) *Reader {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Reader{r: br}
+}
+
+// ReadBaseMessage reads one message and returns its content. See the
+// package-level ReadBaseMessage for details.
+func (r *Reader) ReadBaseMessage() ([]byte, error) {
+	content, _, err := r.ReadBaseMessageWithHeader()
+	return content, err
+}
+
+// ReadBaseMessageWithHeader reads one message and returns its content and
+// the fields of its header. See the package-level ReadBaseMessageWithHeader
+// for details.
+func (r *Reader) ReadBaseMessageWithHeader() ([]byte, Header, error) {
+	maxHeaderLength, maxContentLength := limits(r.MaxHeaderLength, r.MaxContentLength)
+	var content []byte
+	var header Header
+	err := recoverFraming(r.r, r.OnFramingError, func() (err error) {
+		header = make(Header)
+		content, err = readBaseMessage(r.r, maxHeaderLength, maxContentLength, header, nil)
+		return err
+	})
 	if err != nil {
-		return 0, err
+		return nil, nil, err
 	}
-	nextThree := make([]byte, 3)
-	if _, err = io.ReadFull(r, nextThree); err != nil {
-		return 0, err
+	return content, header, nil
+}
+
+// Resync skips to the next Content-Length header, with which messages
+// usually start, and returns the number of bytes discarded. It can be used
+// to resume reading after a read fails with a *BaseProtocolError, which
+// leaves the reader in the middle of a message. If the stream ends before
+// a header is found, it returns the number of bytes discarded and io.EOF.
+func (r *Reader) Resync() (discarded int64, err error) {
+	return resync(r.r)
+}
+
+// ReadProtocolMessage reads a message, decodes and returns it.
+func (r *Reader) ReadProtocolMessage() (Message, error) {
+	content, err := r.ReadBaseMessage()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeProtocolMessage(content)
+}
+
+// resyncMarker is the start of the header that resync looks for.
+const resyncMarker = contentLengthField + ":"
+
+// resync implements Reader.Resync. The header name is matched without
+// regard to case, as readHeader does.
+func resync(r *bufio.Reader) (discarded int64, err error) {
+	for {
+		b, err := r.Peek(len(resyncMarker))
+		if err != nil {
+			// Too few bytes are left for a header.
+			n, _ := r.Discard(len(b))
+			return discarded + int64(n), err
+		}
+		if strings.EqualFold(string(b), resyncMarker) {
+			return discarded, nil
+		}
+		// Skip to the next byte that may start a header.
+		b, _ = r.Peek(r.Buffered())
+		n := len(b)
+		if i := bytes.IndexAny(b[1:], "Cc"); i >= 0 {
+			n = i + 1
+		}
+		r.Discard(n)
+		discarded += int64(n)
 	}
-	if string(nextThree) != "\n\r\n" {
-		return 0, ErrHeaderDelimiterNotCrLfCrLf
+}
+
+// recoverFraming calls read until it succeeds or fails with an error other
+// than a *BaseProtocolError. After each framing error, it skips to the next
+// header with resync and reports the error to onFramingError. If
+// onFramingError is nil, read is called only once.
+func recoverFraming(r *bufio.Reader, onFramingError func(error, int64), read func() error) error {
+	for {
+		err := read()
+		if _, ok := err.(*BaseProtocolError); !ok || onFramingError == nil {
+			return err
+		}
+		n, resyncErr := resync(r)
+		onFramingError(err, n)
+		if resyncErr != nil {
+			return resyncErr
+		}
 	}
+}
 
-	// If header is in the right format, get the length
-	header := strings.TrimSuffix(headerWithCr, "\r")
-	headerAndLength := contentLengthHeaderRegex.FindStringSubmatch(header)
-	if len(headerAndLength) < 2 {
-		return 0, ErrHeaderNotContentLength
+// limits returns the limits to enforce for the MaxHeaderLength and
+// MaxContentLength fields of a Reader or Decoder, replacing zero values with
+// the defaults.
+func limits(maxHeaderLength int, maxContentLength int64) (int, int64) {
+	if maxHeaderLength == 0 {
+		maxHeaderLength = DefaultMaxHeaderLength
+	}
+	if maxContentLength == 0 {
+		maxContentLength = DefaultMaxContentLength
+	}
+	return maxHeaderLength, maxContentLength
+}
+
+// maxPooledContentLength is the capacity above which content buffers are
+// not returned to contentPool, so that a few large messages do not keep
+// large buffers alive.
+const maxPooledContentLength = 64 * 1024
+
+// contentPool holds *[]byte buffers to read the content of messages into.
+var contentPool = sync.Pool{
+	New: func() interface{} { return new([]byte) },
+}
+
+// Decoder reads and decodes protocol messages from an input stream, like
+// json.Decoder does for JSON values. Unlike ReadProtocolMessage, it reads
+// the content of messages into buffers taken from a pool shared by all
+// decoders, which saves allocations when messages are read at a high rate.
+type Decoder struct {
+	r     *bufio.Reader
+	codec *Codec
+
+	// MaxHeaderLength and MaxContentLength limit the size of the messages
+	// read, and OnFramingError enables recovery from framing errors, as the
+	// fields of Reader do.
+	MaxHeaderLength  int
+	MaxContentLength int64
+	OnFramingError   func(err error, discarded int64)
+}
+
+// NewDecoder returns a decoder that reads from r and decodes messages with
+// codec, or with the codec used by DecodeProtocolMessage if codec is nil.
+// If r is a *bufio.Reader, it is used directly.
+func NewDecoder(r io.Reader, codec *Codec) *Decoder {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	if codec == nil {
+		codec = defaultCodec
+	}
+	return &Decoder{r: br, codec: codec}
+}
+
+// Decode reads the next message and decodes it. Errors are those of
+// ReadProtocolMessage.
+func (d *Decoder) Decode() (Message, error) {
+	maxHeaderLength, maxContentLength := limits(d.MaxHeaderLength, d.MaxContentLength)
+	buf := contentPool.Get().(*[]byte)
+	defer contentPool.Put(buf)
+	var content []byte
+	err := recoverFraming(d.r, d.OnFramingError, func() (err error) {
+		content, err = readBaseMessage(d.r, maxHeaderLength, maxContentLength, nil, *buf)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cap(content) > cap(*buf) && cap(content) <= maxPooledContentLength {
+		*buf = content[:0]
 	}
-	return strconv.ParseInt(headerAndLength[1], 10, 64)
+	msg, err := d.codec.DecodeMessage(content)
+	if fieldErr, ok := err.(*DecodeProtocolMessageFieldError); ok {
+		// The error refers to content, which is about to be reused.
+		fieldErr.Message = append(json.RawMessage(nil), fieldErr.Message...)
+	}
+	return msg, err
 }
 
 // WriteProtocolMessage encodes message and writes it to w.
 func WriteProtocolMessage(w io.Writer, message Message) error {
-	b, err := json.Marshal(message)
+	b, err := marshalMessage(message)
 	if err != nil {
 		return err
 	}
 	return WriteBaseMessage(w, b)
 }
 
+// Encoder writes protocol messages to an output stream. It is safe for
+// concurrent use: each message is written as a whole, with a single Write
+// call unless buffering is enabled, so messages written by different
+// goroutines never interleave.
+//
+// Once a write fails, the error is returned by all subsequent calls, as the
+// stream may have been left with a partial message.
+type Encoder struct {
+	mu        sync.Mutex
+	w         io.Writer
+	bw        *bufio.Writer
+	autoFlush bool
+	// waiting counts the writes waiting for mu. It is accessed atomically.
+	waiting int32
+	buf     []byte
+	err     error
+}
+
+// NewEncoder returns an encoder that writes each message to w with a single
+// Write call.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// NewBufferedEncoder returns an encoder that buffers up to size bytes of
+// messages before writing them to w. If autoFlush is true, the buffer is
+// flushed at the end of every write that no other write is waiting for, so
+// messages are never held back, but those written concurrently are sent
+// together. Otherwise, messages are written to w only once the buffer is
+// full or Flush is called.
+//
+// When buffering, a nil error from Encode or WriteBaseMessage only means
+// that the message was buffered: a failure to write it to w is reported by
+// a later call.
+func NewBufferedEncoder(w io.Writer, size int, autoFlush bool) *Encoder {
+	return &Encoder{w: w, bw: bufio.NewWriterSize(w, size), autoFlush: autoFlush}
+}
+
+// Encode encodes message and writes it.
+func (e *Encoder) Encode(message Message) error {
+	b, err := marshalMessage(message)
+	if err != nil {
+		return err
+	}
+	return e.WriteBaseMessage(b)
+}
+
+// WriteBaseMessage writes content with a Content-Length header, as the
+// package-level WriteBaseMessage does.
+func (e *Encoder) WriteBaseMessage(content []byte) error {
+	atomic.AddInt32(&e.waiting, 1)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	waiting := atomic.AddInt32(&e.waiting, -1)
+	if e.err != nil {
+		return e.err
+	}
+
+	e.buf = append(e.buf[:0], contentLengthField+": "...)
+	e.buf = strconv.AppendInt(e.buf, int64(len(content)), 10)
+	e.buf = append(e.buf, crLfcrLf...)
+	if e.bw == nil {
+		e.buf = append(e.buf, content...)
+		_, e.err = e.w.Write(e.buf)
+	} else if _, e.err = e.bw.Write(e.buf); e.err == nil {
+		if _, e.err = e.bw.Write(content); e.err == nil && e.autoFlush && waiting == 0 {
+			e.err = e.bw.Flush()
+		}
+	}
+	if cap(e.buf) > maxPooledContentLength {
+		e.buf = nil
+	}
+	return e.err
+}
+
+// Flush writes the buffered messages to the underlying writer. It does
+// nothing if buffering is disabled.
+func (e *Encoder) Flush() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err == nil && e.bw != nil {
+		e.err = e.bw.Flush()
+	}
+	return e.err
+}
+
+// SeqWriter writes protocol messages to an underlying writer, stamping each
+// one with the next sequence number, starting at 1. It is safe for
+// concurrent use: messages are written one at a time, each with a single
+// Write call, so they appear in the stream in the order of their sequence
+// numbers.
+type SeqWriter struct {
+	mu  sync.Mutex
+	enc *Encoder
+	seq int
+}
+
+// NewSeqWriter returns a SeqWriter that writes to w.
+func NewSeqWriter(w io.Writer) *SeqWriter {
+	return &SeqWriter{enc: NewEncoder(w)}
+}
+
+// WriteMessage sets the Seq of message to the next sequence number, then
+// encodes it and writes it to the underlying writer. message must embed a
+// Request, Response or Event.
+func (sw *SeqWriter) WriteMessage(message Message) error {
+	return sw.writeMessage(message, nil)
+}
+
+// writeMessage is like WriteMessage, but calls beforeWrite once the Seq of
+// message is set and before it is written. If beforeWrite returns an error,
+// message is not written and the sequence number is not used up.
+func (sw *SeqWriter) writeMessage(message Message, beforeWrite func(seq int) error) error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	seq := sw.seq + 1
+	if !setSeq(message, seq) {
+		return fmt.Errorf("cannot set seq of %T", message)
+	}
+	if beforeWrite != nil {
+		if err := beforeWrite(seq); err != nil {
+			return err
+		}
+	}
+	sw.seq = seq
+	return sw.enc.Encode(message)
+}
+
 // ReadProtocolMessage reads a message from r, decodes and returns it.
 func ReadProtocolMessage(r *bufio.Reader) (Message, error) {
 	content, err := ReadBaseMessage(r)
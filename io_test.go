@@ -18,10 +18,12 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"io/ioutil"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -86,7 +88,157 @@ func Test_ReadBaseMessage(t *testing.T) {
 	}
 }
 
-func Test_readContentLengthHeader(t *testing.T) {
+func TestWriteReadBaseMessageWithHeader(t *testing.T) {
+	var buf bytes.Buffer
+	header := Header{}
+	header.Set("content-type", "application/vscode-jsonrpc; charset=utf-8")
+	header.Set("X-Trace", "1")
+	header.Set("Content-Length", "42")
+	if err := WriteBaseMessageWithHeader(&buf, []byte("abc"), header); err != nil {
+		t.Fatal(err)
+	}
+	want := "Content-Length: 3\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\nX-Trace: 1\r\n\r\nabc"
+	if got := buf.String(); got != want {
+		t.Errorf("got written=%q, want %q", got, want)
+	}
+
+	content, gotHeader, err := ReadBaseMessageWithHeader(bufio.NewReader(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "abc" {
+		t.Errorf("got content=%q, want %q", content, "abc")
+	}
+	wantHeader := Header{
+		"Content-Length": "3",
+		"Content-Type":   "application/vscode-jsonrpc; charset=utf-8",
+		"X-Trace":        "1",
+	}
+	if !reflect.DeepEqual(gotHeader, wantHeader) {
+		t.Errorf("got header=%v, want %v", gotHeader, wantHeader)
+	}
+	if got := gotHeader.Get("x-trace"); got != "1" {
+		t.Errorf("got X-Trace=%q, want %q", got, "1")
+	}
+}
+
+func TestReaderLimits(t *testing.T) {
+	longHeader := "X-" + strings.Repeat("a", DefaultMaxHeaderLength) + ": 1\r\n\r\n"
+	tests := []struct {
+		name             string
+		input            string
+		maxHeaderLength  int
+		maxContentLength int64
+		wantBytesRead    []byte
+		wantErr          error
+	}{
+		{"default content limit", "Content-Length: 4194305\r\n\r\nabc", 0, 0, nil, ErrHeaderContentTooLong},
+		{"content within limit", "Content-Length: 3\r\n\r\nabc", 0, 3, []byte("abc"), nil},
+		{"content over limit", "Content-Length: 4\r\n\r\nabcd", 0, 3, nil, ErrHeaderContentTooLong},
+		{"content unlimited", "Content-Length: 4194305\r\n\r\nabc", 0, -1, nil, io.ErrUnexpectedEOF},
+		{"header within limit", "Content-Length: 3\r\n\r\nabc", 17, 0, []byte("abc"), nil},
+		{"header over limit", "Content-Length: 3\r\n\r\nabc", 16, 0, nil, ErrHeaderTooLong},
+		{"fields within limit", "Content-Length: 3\r\nX: 1\r\n\r\nabc", 23, 0, []byte("abc"), nil},
+		{"fields over limit", "Content-Length: 3\r\nX: 1\r\n\r\nabc", 22, 0, nil, ErrHeaderTooLong},
+		{"default header limit", longHeader, 0, 0, nil, ErrHeaderTooLong},
+		{"header unlimited", longHeader, -1, 0, nil, ErrHeaderNotContentLength},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(test.input))
+			r.MaxHeaderLength = test.maxHeaderLength
+			r.MaxContentLength = test.maxContentLength
+			gotBytes, gotErr := r.ReadBaseMessage()
+			if gotErr != test.wantErr {
+				t.Errorf("got err=%#v, want %#v", gotErr, test.wantErr)
+			}
+			if gotErr == nil && !bytes.Equal(gotBytes, test.wantBytesRead) {
+				t.Errorf("got bytes=%q, want %q", gotBytes, test.wantBytesRead)
+			}
+		})
+	}
+}
+
+func TestReaderResync(t *testing.T) {
+	type framingError struct {
+		err       error
+		discarded int64
+	}
+	tests := []struct {
+		name        string
+		input       string
+		wantContent []string
+		wantErrors  []framingError
+	}{
+		{
+			name:        "malformed field",
+			input:       "garbage\r\n\r\nContent-Length: 3\r\n\r\nabc",
+			wantContent: []string{"abc"},
+			wantErrors:  []framingError{{ErrHeaderNotContentLength, 0}},
+		},
+		{
+			name:        "malformed delimiter",
+			input:       "Content-Length: 3\r\r\rxyzcontent-length: 2\r\n\r\n{}",
+			wantContent: []string{"{}"},
+			wantErrors:  []framingError{{ErrHeaderDelimiterNotCrLfCrLf, 2}},
+		},
+		{
+			name:        "content too long",
+			input:       "Content-Length: 3\r\n\r\nabcContent-Length: 5\r\n\r\nabcdeContent-Length: 1\r\n\r\nx",
+			wantContent: []string{"abc", "x"},
+			wantErrors:  []framingError{{ErrHeaderContentTooLong, 5}},
+		},
+		{
+			name:       "trailing garbage",
+			input:      "Content-Length: x\r\n\r\n{}",
+			wantErrors: []framingError{{ErrHeaderNotContentLength, 2}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotErrors []framingError
+			r := NewReader(strings.NewReader(test.input))
+			r.MaxContentLength = 4
+			r.OnFramingError = func(err error, discarded int64) {
+				gotErrors = append(gotErrors, framingError{err, discarded})
+			}
+			var gotContent []string
+			for {
+				content, err := r.ReadBaseMessage()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotContent = append(gotContent, string(content))
+			}
+			if !reflect.DeepEqual(gotContent, test.wantContent) {
+				t.Errorf("got content %q, want %q", gotContent, test.wantContent)
+			}
+			if !reflect.DeepEqual(gotErrors, test.wantErrors) {
+				t.Errorf("got framing errors %v, want %v", gotErrors, test.wantErrors)
+			}
+		})
+	}
+
+	// Without OnFramingError, Resync is called explicitly.
+	r := NewReader(strings.NewReader("Content-Length 3\r\n\r\nabcContent-Length: 3\r\n\r\nabc"))
+	if _, err := r.ReadBaseMessage(); err != ErrHeaderNotContentLength {
+		t.Fatalf("got err=%v, want %v", err, ErrHeaderNotContentLength)
+	}
+	if n, err := r.Resync(); n != 3 || err != nil {
+		t.Errorf("got n=%d, err=%v, want 3 bytes discarded", n, err)
+	}
+	if content, err := r.ReadBaseMessage(); string(content) != "abc" || err != nil {
+		t.Errorf("got content=%q, err=%v, want abc", content, err)
+	}
+	if n, err := r.Resync(); n != 0 || err != io.EOF {
+		t.Errorf("got n=%d, err=%v, want EOF", n, err)
+	}
+}
+
+func Test_readHeader(t *testing.T) {
 	tests := []struct {
 		input         string
 		wantBytesLeft string // Bytes left in the reader after header reading
@@ -118,11 +270,23 @@ func Test_readContentLengthHeader(t *testing.T) {
 		{"Content-Length: 0\r\n\r\nabc", "abc", 0, nil},
 		{"Content-Length: 3\r\n\r\nabc", "abc", 3, nil},
 		{"Content-Length: 9223372036854775807\r\n\r\nabc", "abc", 9223372036854775807, nil},
+		{"Content-Length: 9223372036854775808\r\n\r\nabc", "abc", 0, ErrHeaderNotContentLength},
+		{"Content-Length: +3\r\n\r\nabc", "abc", 0, ErrHeaderNotContentLength},
+		{"Content-Length:\r\n\r\nabc", "abc", 0, ErrHeaderNotContentLength},
+		{"content-length:3\r\n\r\nabc", "abc", 3, nil},
+		{"CONTENT-LENGTH :  3 \r\n\r\nabc", "abc", 3, nil},
+		{"Content-Type: application/vscode-jsonrpc; charset=utf-8\r\nContent-Length: 3\r\n\r\nabc", "abc", 3, nil},
+		{"Content-Length: 3\r\nX-Foo: bar\r\n\r\nabc", "abc", 3, nil},
+		{"X-Foo\r\nContent-Length: 3\r\n\r\nabc", "abc", 0, ErrHeaderNotContentLength},
+		{"Content-Length: 3\r\nX-Foo\r\n\r\nabc", "Foo\r\n\r\nabc", 0, ErrHeaderDelimiterNotCrLfCrLf},
+		{"Content-Length: 3\r\n: bar\r\n\r\nabc", "bar\r\n\r\nabc", 0, ErrHeaderDelimiterNotCrLfCrLf},
+		{"Content-Length: 3\r\nX-Foo: bar\r\nabc", "c", 0, ErrHeaderDelimiterNotCrLfCrLf},
+		{"Content-Length: 3\r\nX-Foo: bar\r\n", "", 0, io.ErrUnexpectedEOF},
 	}
 	for _, test := range tests {
 		t.Run(test.input, func(t *testing.T) {
 			reader := bufio.NewReader(strings.NewReader(test.input))
-			gotLen, gotErr := readContentLengthHeader(reader)
+			gotLen, gotErr := readHeader(reader, DefaultMaxHeaderLength, nil)
 			if gotErr != test.wantErr {
 				t.Errorf("got err=%#v, want %#v", gotErr, test.wantErr)
 			}
@@ -327,3 +491,231 @@ func TestReadProtocolMessage(t *testing.T) {
 		t.Errorf("got req=%#v, want %#v", msg, &cancelReqStruct)
 	}
 }
+
+func TestDecoder(t *testing.T) {
+	var buf bytes.Buffer
+	for _, content := range []string{
+		cancelReqString[strings.Index(cancelReqString, "{"):],
+		`{"seq":2,"type":"request","command":"foo"}`,
+		`{"seq":3,"type":"event","event":"output","body":{"output":"hello"}}`,
+	} {
+		if err := WriteBaseMessage(&buf, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := NewDecoder(&buf, nil)
+
+	msg, err := d.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(msg, &cancelReqStruct) {
+		t.Errorf("got req=%#v, want %#v", msg, &cancelReqStruct)
+	}
+	_, err = d.Decode()
+	var fieldErr *DecodeProtocolMessageFieldError
+	if !errors.As(err, &fieldErr) || fieldErr.FieldValue != "foo" {
+		t.Fatalf("got err=%v, want unsupported command foo", err)
+	}
+	msg, err = d.Decode()
+	if e, ok := msg.(*OutputEvent); !ok || err != nil || e.Body.Output != "hello" {
+		t.Errorf("got msg=%#v, err=%v, want output event", msg, err)
+	}
+	// The content referred to by the error is not overwritten by later reads.
+	if got, want := string(fieldErr.Message), `{"seq":2,"type":"request","command":"foo"}`; got != want {
+		t.Errorf("got error message=%s, want %s", got, want)
+	}
+	if _, err := d.Decode(); err != io.EOF {
+		t.Errorf("got err=%v, want EOF", err)
+	}
+
+	d = NewDecoder(strings.NewReader("Content-Length: 4\r\n\r\n{}{}"), nil)
+	d.MaxContentLength = 3
+	if _, err := d.Decode(); err != ErrHeaderContentTooLong {
+		t.Errorf("got err=%v, want %v", err, ErrHeaderContentTooLong)
+	}
+}
+
+// repeatReader reads b over and over again.
+type repeatReader struct {
+	b   []byte
+	off int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		c := copy(p[n:], r.b[r.off:])
+		n += c
+		r.off = (r.off + c) % len(r.b)
+	}
+	return n, nil
+}
+
+// outputEventMessage returns an encoded output event, as streamed by debug
+// adapters at a high rate.
+func outputEventMessage(b *testing.B) []byte {
+	var buf bytes.Buffer
+	e := &OutputEvent{
+		Event: *newEvent(1, "output"),
+		Body:  OutputEventBody{Category: "stdout", Output: strings.Repeat("output line\n", 20)},
+	}
+	if err := WriteProtocolMessage(&buf, e); err != nil {
+		b.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func BenchmarkReadProtocolMessage(b *testing.B) {
+	msg := outputEventMessage(b)
+	r := bufio.NewReader(&repeatReader{b: msg})
+	b.SetBytes(int64(len(msg)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ReadProtocolMessage(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	msg := outputEventMessage(b)
+	d := NewDecoder(&repeatReader{b: msg}, nil)
+	b.SetBytes(int64(len(msg)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := d.Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// writeRecorder records every Write call made to it.
+type writeRecorder struct {
+	mu     sync.Mutex
+	writes []string
+	err    error
+}
+
+func (w *writeRecorder) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *writeRecorder) joined() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Join(w.writes, "")
+}
+
+func TestEncoder(t *testing.T) {
+	w := new(writeRecorder)
+	e := NewEncoder(w)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := e.Encode(&OutputEvent{Event: *newEvent(i, "output"), Body: OutputEventBody{Output: strings.Repeat("x", 1000)}}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(w.writes) != 10 {
+		t.Fatalf("got %d writes, want 10", len(w.writes))
+	}
+	for _, write := range w.writes {
+		r := bufio.NewReader(strings.NewReader(write))
+		if _, err := ReadProtocolMessage(r); err != nil || r.Buffered() != 0 {
+			t.Errorf("got write %q, want exactly one message", write)
+		}
+	}
+
+	w.err = errors.New("broken pipe")
+	if err := e.Encode(&ThreadsRequest{Request: *newRequest(1, "threads")}); err != w.err {
+		t.Errorf("got err=%v, want %v", err, w.err)
+	}
+	w.err = nil
+	if err := e.Encode(&ThreadsRequest{Request: *newRequest(2, "threads")}); err == nil {
+		t.Error("got nil error after a failed write, want error")
+	}
+}
+
+func TestBufferedEncoder(t *testing.T) {
+	w := new(writeRecorder)
+	e := NewBufferedEncoder(w, 4096, false)
+	for i := 1; i <= 3; i++ {
+		if err := e.WriteBaseMessage([]byte("abc")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(w.writes) != 0 {
+		t.Errorf("got writes %q before Flush, want none", w.writes)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := w.joined(), strings.Repeat("Content-Length: 3\r\n\r\nabc", 3); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	w = new(writeRecorder)
+	e = NewBufferedEncoder(w, 4096, true)
+	if err := e.WriteBaseMessage([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := w.joined(), "Content-Length: 3\r\n\r\nabc"; got != want {
+		t.Errorf("got %q without Flush, want %q", got, want)
+	}
+
+	w.err = errors.New("broken pipe")
+	if err := e.WriteBaseMessage([]byte("abc")); err != w.err {
+		t.Errorf("got err=%v, want %v", err, w.err)
+	}
+	if err := e.Flush(); err != w.err {
+		t.Errorf("got err=%v from Flush, want %v", err, w.err)
+	}
+}
+
+func TestSeqWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	sw := NewSeqWriter(buf)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sw.WriteMessage(&OutputEvent{Event: *newEvent(0, "output")}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := sw.WriteMessage(&ThreadsResponse{Response: *newResponse(0, 1, "threads", true)}); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(buf)
+	for want := 1; want <= 11; want++ {
+		msg, err := ReadProtocolMessage(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := msg.GetSeq(); got != want {
+			t.Errorf("got seq=%d, want %d", got, want)
+		}
+	}
+
+	if err := sw.WriteMessage(&ProtocolMessage{}); err == nil {
+		t.Error("got nil error writing a bare ProtocolMessage, want error")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains helpers for filling in the protocol fields shared by
+// all messages of a kind, such as Seq, Type, Command and Event, and for
+// building the responses to requests of any type.
+
+package dap
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	messageNamesOnce sync.Once
+	messageNames     map[reflect.Type]string
+)
+
+// messageName returns the command or event name of the type of m as defined
+// by the specification, or an empty string if m is of a custom type.
+func messageName(m Message) string {
+	messageNamesOnce.Do(func() {
+		messageNames = make(map[reflect.Type]string, len(requestCtor)+len(responseCtor)+len(eventCtor))
+		for _, ctors := range []map[string]messageCtor{requestCtor, responseCtor, eventCtor} {
+			for name, ctor := range ctors {
+				messageNames[reflect.TypeOf(ctor())] = name
+			}
+		}
+	})
+	return messageNames[reflect.TypeOf(m)]
+}
+
+// fillHeader sets the Type of m according to its kind, and its Command or
+// Event if it is empty and m is of a type defined by the specification.
+func fillHeader(m Message) {
+	switch m := m.(type) {
+	case RequestMessage:
+		r := m.GetRequest()
+		r.Type = "request"
+		if r.Command == "" {
+			r.Command = messageName(m)
+		}
+	case ResponseMessage:
+		r := m.GetResponse()
+		r.Type = "response"
+		if r.Command == "" {
+			r.Command = messageName(m)
+		}
+	case EventMessage:
+		e := m.GetEvent()
+		e.Type = "event"
+		if e.Event == "" {
+			e.Event = messageName(m)
+		}
+	}
+}
+
+// setSeq sets the Seq of m. It reports false if m is not a request, response
+// or event, in which case its Seq cannot be set.
+func setSeq(m Message, seq int) bool {
+	switch m := m.(type) {
+	case RequestMessage:
+		m.GetRequest().Seq = seq
+	case ResponseMessage:
+		m.GetResponse().Seq = seq
+	case EventMessage:
+		m.GetEvent().Seq = seq
+	default:
+		return false
+	}
+	return true
+}
+
+// NewResponse returns an empty successful response to req, of the type the
+// specification defines for its command, with Type, Command and RequestSeq
+// filled in. This lets a handler reply to any request without knowing its
+// type. The response to a command the specification does not define is a
+// *GenericResponse; use Codec.NewResponse for custom commands.
+func NewResponse(req RequestMessage) ResponseMessage {
+	return defaultCodec.NewResponse(req)
+}
+
+// NewErrorResponse returns an ErrorResponse to req with message, the short
+// form of the error, and err, the structured error the client may show to
+// the user, which can be nil.
+func NewErrorResponse(req RequestMessage, message string, err *ErrorMessage) *ErrorResponse {
+	r := req.GetRequest()
+	er := &ErrorResponse{Body: ErrorResponseBody{Error: err}}
+	er.Type = "response"
+	er.Command = r.Command
+	er.RequestSeq = r.Seq
+	er.Message = message
+	return er
+}
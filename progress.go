@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains a helper for reporting the progress of long-running
+// operations with progressStart, progressUpdate and progressEnd events.
+
+package dap
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// Progress reports the progress of a long-running operation to the client
+// of a Session. It is created with Session.StartProgress, which sends the
+// progressStart event, and ends with exactly one progressEnd event. It is
+// safe for concurrent use.
+//
+// If the client did not announce supportsProgressReporting in its
+// initialize request, no events are sent, but the Progress can be used all
+// the same.
+type Progress struct {
+	s           *Session
+	id          string
+	enabled     bool
+	cancellable bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	ended bool
+}
+
+// StartProgress starts reporting the progress of an operation with the
+// given title and sends the progressStart event. If ctx belongs to a request
+// being handled by the session, the progress is associated with it.
+//
+// The progress ends automatically, if End was not called before, once ctx
+// is done. In particular, progress started with the context of a handler
+// ends when the handler returns, even by panicking or after the request was
+// cancelled.
+//
+// If cancellable is true, the client may cancel the progress with a cancel
+// request, which cancels the context returned by Progress.Context and thus
+// ends the progress.
+func (s *Session) StartProgress(ctx context.Context, title string, cancellable bool) *Progress {
+	p := &Progress{s: s, cancellable: cancellable}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+
+	s.mu.Lock()
+	s.progressID++
+	p.id = strconv.Itoa(s.progressID)
+	p.enabled = s.initArgs != nil && s.initArgs.SupportsProgressReporting
+	s.progress[p.id] = p
+	s.mu.Unlock()
+
+	if p.enabled {
+		body := ProgressStartEventBody{ProgressId: p.id, Title: title, Cancellable: cancellable}
+		if rs, ok := ctx.Value(requestStateKey{}).(*requestState); ok {
+			body.RequestId = rs.seq
+		}
+		s.Send(&ProgressStartEvent{Body: body})
+	}
+	go func() {
+		<-p.ctx.Done()
+		p.End("")
+	}()
+	return p
+}
+
+// ID returns the progressId identifying the progress in events.
+func (p *Progress) ID() string {
+	return p.id
+}
+
+// Context returns a context that is done once the progress ends or is
+// cancelled by the client.
+func (p *Progress) Context() context.Context {
+	return p.ctx
+}
+
+// Update sends a progressUpdate event with message and percentage, which
+// is clamped to the range [0, 100]. It does nothing once the progress ended.
+func (p *Progress) Update(message string, percentage int) {
+	if percentage < 0 {
+		percentage = 0
+	} else if percentage > 100 {
+		percentage = 100
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ended || !p.enabled {
+		return
+	}
+	p.s.Send(&ProgressUpdateEvent{Body: ProgressUpdateEventBody{ProgressId: p.id, Message: message, Percentage: percentage}})
+}
+
+// End sends the progressEnd event with an optional final message. Only the
+// first call has an effect.
+func (p *Progress) End(message string) {
+	p.mu.Lock()
+	if p.ended {
+		p.mu.Unlock()
+		return
+	}
+	p.ended = true
+	if p.enabled {
+		p.s.Send(&ProgressEndEvent{Body: ProgressEndEventBody{ProgressId: p.id, Message: message}})
+	}
+	p.mu.Unlock()
+
+	p.s.mu.Lock()
+	delete(p.s.progress, p.id)
+	p.s.mu.Unlock()
+	p.cancel()
+}
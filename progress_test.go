@@ -0,0 +1,117 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dap
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// progressHandler reports progress while handling evaluate and stackTrace
+// requests.
+type progressHandler struct {
+	UnimplementedHandler
+	s *Session
+}
+
+func (h *progressHandler) OnInitializeRequest(ctx context.Context, req *InitializeRequest) (*InitializeResponse, error) {
+	return nil, nil
+}
+
+func (h *progressHandler) OnEvaluateRequest(ctx context.Context, req *EvaluateRequest) (*EvaluateResponse, error) {
+	p := h.s.StartProgress(ctx, "Evaluating", true)
+	p.Update("halfway", 150)
+	<-p.Context().Done()
+	return nil, p.Context().Err()
+}
+
+func (h *progressHandler) OnStackTraceRequest(ctx context.Context, req *StackTraceRequest) (*StackTraceResponse, error) {
+	h.s.StartProgress(ctx, "Loading stack", false)
+	panic("boom")
+}
+
+func TestProgress(t *testing.T) {
+	c := startSession(t, func(s *Session) Handler { return &progressHandler{s: s} })
+	if _, err := c.Call(&InitializeRequest{Arguments: InitializeRequestArguments{SupportsProgressReporting: true}}); err != nil {
+		t.Fatal(err)
+	}
+
+	evaluate := &EvaluateRequest{Arguments: EvaluateArguments{Expression: "x"}}
+	done := make(chan error)
+	go func() {
+		_, err := c.Call(evaluate)
+		done <- err
+	}()
+	e := <-c.Events()
+	start, ok := e.(*ProgressStartEvent)
+	if !ok || start.Body.Title != "Evaluating" || !start.Body.Cancellable || start.Body.RequestId != 2 {
+		t.Fatalf("got %#v, want cancellable progressStart for request 2", e)
+	}
+	id := start.Body.ProgressId
+	e = <-c.Events()
+	if update, ok := e.(*ProgressUpdateEvent); !ok || update.Body.ProgressId != id || update.Body.Message != "halfway" || update.Body.Percentage != 100 {
+		t.Errorf("got %#v, want progressUpdate at 100%%", e)
+	}
+
+	if _, err := c.Call(&CancelRequest{Arguments: &CancelArguments{ProgressId: id}}); err != nil {
+		t.Fatal(err)
+	}
+	e = <-c.Events()
+	if end, ok := e.(*ProgressEndEvent); !ok || end.Body.ProgressId != id {
+		t.Errorf("got %#v, want progressEnd", e)
+	}
+	if err := <-done; err == nil {
+		t.Error("got nil error from cancelled evaluate, want error")
+	}
+
+	// Progress ends even if the handler panics.
+	_, err := c.Call(&StackTraceRequest{})
+	var re *ResponseError
+	if !errors.As(err, &re) || re.Response.Message != "panic: boom" {
+		t.Errorf("got err=%v, want panic: boom", err)
+	}
+	e = <-c.Events()
+	start, ok = e.(*ProgressStartEvent)
+	if !ok || start.Body.Cancellable {
+		t.Fatalf("got %#v, want progressStart", e)
+	}
+	e = <-c.Events()
+	if end, ok := e.(*ProgressEndEvent); !ok || end.Body.ProgressId != start.Body.ProgressId {
+		t.Errorf("got %#v, want progressEnd", e)
+	}
+}
+
+func TestProgressNotSupported(t *testing.T) {
+	var s *Session
+	c := startSession(t, func(session *Session) Handler {
+		s = session
+		return &progressHandler{s: s}
+	})
+	if _, err := c.Call(&InitializeRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	p := s.StartProgress(context.Background(), "Loading", false)
+	p.Update("loading", 10)
+	p.End("done")
+	if p.Context().Err() == nil {
+		t.Error("got live context after End, want done")
+	}
+	s.Send(&OutputEvent{Body: OutputEventBody{Output: "after"}})
+	if e, ok := (<-c.Events()).(*OutputEvent); !ok {
+		t.Errorf("got %#v, want output event only", e)
+	}
+}
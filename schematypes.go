@@ -18,7 +18,10 @@
 
 package dap
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+)
 
 // Message is an interface that all DAP message types implement with pointer
 // receivers. It's not part of the protocol but is used to enforce static
@@ -97,6 +100,12 @@ type Response struct {
 	Message    string `json:"message,omitempty"`
 }
 
+// Values of Response.Message.
+const (
+	ResponseMessageCancelled  = "cancelled"
+	ResponseMessageNotStopped = "notStopped"
+)
+
 func (r *Response) GetResponse() *Response { return r }
 
 // ErrorResponse: On error (whenever `success` is false), the body can provide more details.
@@ -168,6 +177,19 @@ type StoppedEventBody struct {
 	HitBreakpointIds  []int  `json:"hitBreakpointIds,omitempty"`
 }
 
+// Values of StoppedEventBody.Reason.
+const (
+	StoppedEventBodyReasonStep                  = "step"
+	StoppedEventBodyReasonBreakpoint            = "breakpoint"
+	StoppedEventBodyReasonException             = "exception"
+	StoppedEventBodyReasonPause                 = "pause"
+	StoppedEventBodyReasonEntry                 = "entry"
+	StoppedEventBodyReasonGoto                  = "goto"
+	StoppedEventBodyReasonFunctionBreakpoint    = "function breakpoint"
+	StoppedEventBodyReasonDataBreakpoint        = "data breakpoint"
+	StoppedEventBodyReasonInstructionBreakpoint = "instruction breakpoint"
+)
+
 // ContinuedEvent: The event indicates that the execution of the debuggee has continued.
 // Please note: a debug adapter is not expected to send this event in response to a request that implies that execution continues, e.g. `launch` or `continue`.
 // It is only necessary to send a `continued` event if there was no previous request that implied this.
@@ -216,6 +238,12 @@ type ThreadEventBody struct {
 	ThreadId int    `json:"threadId"`
 }
 
+// Values of ThreadEventBody.Reason.
+const (
+	ThreadEventBodyReasonStarted = "started"
+	ThreadEventBodyReasonExited  = "exited"
+)
+
 // OutputEvent: The event indicates that the target has produced some output.
 type OutputEvent struct {
 	Event
@@ -234,6 +262,22 @@ type OutputEventBody struct {
 	Data               json.RawMessage `json:"data,omitempty"`
 }
 
+// Values of OutputEventBody.Category.
+const (
+	OutputEventBodyCategoryConsole   = "console"
+	OutputEventBodyCategoryImportant = "important"
+	OutputEventBodyCategoryStdout    = "stdout"
+	OutputEventBodyCategoryStderr    = "stderr"
+	OutputEventBodyCategoryTelemetry = "telemetry"
+)
+
+// Values of OutputEventBody.Group.
+const (
+	OutputEventBodyGroupStart          = "start"
+	OutputEventBodyGroupStartCollapsed = "startCollapsed"
+	OutputEventBodyGroupEnd            = "end"
+)
+
 // BreakpointEvent: The event indicates that some information about a breakpoint has changed.
 type BreakpointEvent struct {
 	Event
@@ -246,6 +290,13 @@ type BreakpointEventBody struct {
 	Breakpoint Breakpoint `json:"breakpoint"`
 }
 
+// Values of BreakpointEventBody.Reason.
+const (
+	BreakpointEventBodyReasonChanged = "changed"
+	BreakpointEventBodyReasonNew     = "new"
+	BreakpointEventBodyReasonRemoved = "removed"
+)
+
 // ModuleEvent: The event indicates that some information about a module has changed.
 type ModuleEvent struct {
 	Event
@@ -258,6 +309,13 @@ type ModuleEventBody struct {
 	Module Module `json:"module"`
 }
 
+// Values of ModuleEventBody.Reason.
+const (
+	ModuleEventBodyReasonNew     = "new"
+	ModuleEventBodyReasonChanged = "changed"
+	ModuleEventBodyReasonRemoved = "removed"
+)
+
 // LoadedSourceEvent: The event indicates that some source has been added, changed, or removed from the set of all loaded sources.
 type LoadedSourceEvent struct {
 	Event
@@ -270,6 +328,13 @@ type LoadedSourceEventBody struct {
 	Source Source `json:"source"`
 }
 
+// Values of LoadedSourceEventBody.Reason.
+const (
+	LoadedSourceEventBodyReasonNew     = "new"
+	LoadedSourceEventBodyReasonChanged = "changed"
+	LoadedSourceEventBodyReasonRemoved = "removed"
+)
+
 // ProcessEvent: The event indicates that the debugger has begun debugging a new process. Either one that it has launched, or one that it has attached to.
 type ProcessEvent struct {
 	Event
@@ -285,6 +350,13 @@ type ProcessEventBody struct {
 	PointerSize     int    `json:"pointerSize,omitempty"`
 }
 
+// Values of ProcessEventBody.StartMethod.
+const (
+	ProcessEventBodyStartMethodLaunch                   = "launch"
+	ProcessEventBodyStartMethodAttach                   = "attach"
+	ProcessEventBodyStartMethodAttachForSuspendedLaunch = "attachForSuspendedLaunch"
+)
+
 // CapabilitiesEvent: The event indicates that one or more capabilities have changed.
 // Since the capabilities are dependent on the client and its UI, it might not be possible to change that at random times (or too late).
 // Consequently this event has a hint characteristic: a client can only be expected to make a 'best effort' in honoring individual capabilities but there are no guarantees.
@@ -388,14 +460,20 @@ type RunInTerminalRequest struct {
 
 // RunInTerminalRequestArguments: Arguments for `runInTerminal` request.
 type RunInTerminalRequestArguments struct {
-	Kind                        string         `json:"kind,omitempty"`
-	Title                       string         `json:"title,omitempty"`
-	Cwd                         string         `json:"cwd"`
-	Args                        []string       `json:"args"`
-	Env                         map[string]any `json:"env,omitempty"`
-	ArgsCanBeInterpretedByShell bool           `json:"argsCanBeInterpretedByShell,omitempty"`
+	Kind                        string                    `json:"kind,omitempty"`
+	Title                       string                    `json:"title,omitempty"`
+	Cwd                         string                    `json:"cwd"`
+	Args                        []string                  `json:"args"`
+	Env                         map[string]NullableString `json:"env,omitempty"`
+	ArgsCanBeInterpretedByShell bool                      `json:"argsCanBeInterpretedByShell,omitempty"`
 }
 
+// Values of RunInTerminalRequestArguments.Kind.
+const (
+	RunInTerminalRequestArgumentsKindIntegrated = "integrated"
+	RunInTerminalRequestArgumentsKindExternal   = "external"
+)
+
 // RunInTerminalResponse: Response to `runInTerminal` request.
 type RunInTerminalResponse struct {
 	Response
@@ -423,6 +501,12 @@ type StartDebuggingRequestArguments struct {
 	Request       string         `json:"request"`
 }
 
+// Values of StartDebuggingRequestArguments.Request.
+const (
+	StartDebuggingRequestArgumentsRequestLaunch = "launch"
+	StartDebuggingRequestArgumentsRequestAttach = "attach"
+)
+
 // StartDebuggingResponse: Response to `startDebugging` request. This is just an acknowledgement, so no body field is required.
 type StartDebuggingResponse struct {
 	Response
@@ -458,6 +542,12 @@ type InitializeRequestArguments struct {
 	SupportsStartDebuggingRequest       bool   `json:"supportsStartDebuggingRequest,omitempty"`
 }
 
+// Values of InitializeRequestArguments.PathFormat.
+const (
+	InitializeRequestArgumentsPathFormatPath = "path"
+	InitializeRequestArgumentsPathFormatUri  = "uri"
+)
+
 // InitializeResponse: Response to `initialize` request.
 type InitializeResponse struct {
 	Response
@@ -708,7 +798,7 @@ type DataBreakpointInfoResponse struct {
 }
 
 type DataBreakpointInfoResponseBody struct {
-	DataId      any                        `json:"dataId"`
+	DataId      NullableString             `json:"dataId"`
 	Description string                     `json:"description"`
 	AccessTypes []DataBreakpointAccessType `json:"accessTypes,omitempty"`
 	CanPersist  bool                       `json:"canPersist,omitempty"`
@@ -1027,6 +1117,12 @@ type VariablesArguments struct {
 	Format             *ValueFormat `json:"format,omitempty"`
 }
 
+// Values of VariablesArguments.Filter.
+const (
+	VariablesArgumentsFilterIndexed = "indexed"
+	VariablesArgumentsFilterNamed   = "named"
+)
+
 // VariablesResponse: Response to `variables` request.
 type VariablesResponse struct {
 	Response
@@ -1193,6 +1289,15 @@ type EvaluateArguments struct {
 	Format     *ValueFormat `json:"format,omitempty"`
 }
 
+// Values of EvaluateArguments.Context.
+const (
+	EvaluateArgumentsContextWatch     = "watch"
+	EvaluateArgumentsContextRepl      = "repl"
+	EvaluateArgumentsContextHover     = "hover"
+	EvaluateArgumentsContextClipboard = "clipboard"
+	EvaluateArgumentsContextVariables = "variables"
+)
+
 // EvaluateResponse: Response to `evaluate` request.
 type EvaluateResponse struct {
 	Response
@@ -1506,16 +1611,16 @@ type ErrorMessage struct {
 //
 // To avoid an unnecessary proliferation of additional attributes with similar semantics but different names, we recommend to re-use attributes from the 'recommended' list below first, and only introduce new attributes if nothing appropriate could be found.
 type Module struct {
-	Id             any    `json:"id"`
-	Name           string `json:"name"`
-	Path           string `json:"path,omitempty"`
-	IsOptimized    bool   `json:"isOptimized,omitempty"`
-	IsUserCode     bool   `json:"isUserCode,omitempty"`
-	Version        string `json:"version,omitempty"`
-	SymbolStatus   string `json:"symbolStatus,omitempty"`
-	SymbolFilePath string `json:"symbolFilePath,omitempty"`
-	DateTimeStamp  string `json:"dateTimeStamp,omitempty"`
-	AddressRange   string `json:"addressRange,omitempty"`
+	Id             IntOrString `json:"id"`
+	Name           string      `json:"name"`
+	Path           string      `json:"path,omitempty"`
+	IsOptimized    bool        `json:"isOptimized,omitempty"`
+	IsUserCode     bool        `json:"isUserCode,omitempty"`
+	Version        string      `json:"version,omitempty"`
+	SymbolStatus   string      `json:"symbolStatus,omitempty"`
+	SymbolFilePath string      `json:"symbolFilePath,omitempty"`
+	DateTimeStamp  string      `json:"dateTimeStamp,omitempty"`
+	AddressRange   string      `json:"addressRange,omitempty"`
 }
 
 // ColumnDescriptor: A `ColumnDescriptor` specifies what module attribute to show in a column of the modules view, how to format it,
@@ -1529,6 +1634,14 @@ type ColumnDescriptor struct {
 	Width         int    `json:"width,omitempty"`
 }
 
+// Values of ColumnDescriptor.Type.
+const (
+	ColumnDescriptorTypeString           = "string"
+	ColumnDescriptorTypeNumber           = "number"
+	ColumnDescriptorTypeBoolean          = "boolean"
+	ColumnDescriptorTypeUnixTimestampUTC = "unixTimestampUTC"
+)
+
 // ModulesViewDescriptor: The ModulesViewDescriptor is the container for all declarative configuration options of a module view.
 // For now it only specifies the columns to be shown in the modules view.
 type ModulesViewDescriptor struct {
@@ -1554,20 +1667,34 @@ type Source struct {
 	Checksums        []Checksum      `json:"checksums,omitempty"`
 }
 
+// Values of Source.PresentationHint.
+const (
+	SourcePresentationHintNormal      = "normal"
+	SourcePresentationHintEmphasize   = "emphasize"
+	SourcePresentationHintDeemphasize = "deemphasize"
+)
+
 // StackFrame: A Stackframe contains the source location.
 type StackFrame struct {
-	Id                          int     `json:"id"`
-	Name                        string  `json:"name"`
-	Source                      *Source `json:"source,omitempty"`
-	Line                        int     `json:"line"`
-	Column                      int     `json:"column"`
-	EndLine                     int     `json:"endLine,omitempty"`
-	EndColumn                   int     `json:"endColumn,omitempty"`
-	CanRestart                  bool    `json:"canRestart,omitempty"`
-	InstructionPointerReference string  `json:"instructionPointerReference,omitempty"`
-	ModuleId                    any     `json:"moduleId,omitempty"`
-	PresentationHint            string  `json:"presentationHint,omitempty"`
-}
+	Id                          int          `json:"id"`
+	Name                        string       `json:"name"`
+	Source                      *Source      `json:"source,omitempty"`
+	Line                        int          `json:"line"`
+	Column                      int          `json:"column"`
+	EndLine                     int          `json:"endLine,omitempty"`
+	EndColumn                   int          `json:"endColumn,omitempty"`
+	CanRestart                  bool         `json:"canRestart,omitempty"`
+	InstructionPointerReference string       `json:"instructionPointerReference,omitempty"`
+	ModuleId                    *IntOrString `json:"moduleId,omitempty"`
+	PresentationHint            string       `json:"presentationHint,omitempty"`
+}
+
+// Values of StackFrame.PresentationHint.
+const (
+	StackFramePresentationHintNormal = "normal"
+	StackFramePresentationHintLabel  = "label"
+	StackFramePresentationHintSubtle = "subtle"
+)
 
 // Scope: A `Scope` is a named container for variables. Optionally a scope can map to a source or a range within a source.
 type Scope struct {
@@ -1584,6 +1711,13 @@ type Scope struct {
 	EndColumn          int     `json:"endColumn,omitempty"`
 }
 
+// Values of Scope.PresentationHint.
+const (
+	ScopePresentationHintArguments = "arguments"
+	ScopePresentationHintLocals    = "locals"
+	ScopePresentationHintRegisters = "registers"
+)
+
 // Variable: A Variable is a name/value pair.
 // The `type` attribute is shown if space permits or when hovering over the variable's name.
 // The `kind` attribute is used to render additional properties of the variable, e.g. different icons can be used to indicate that a variable is public or private.
@@ -1610,6 +1744,42 @@ type VariablePresentationHint struct {
 	Lazy       bool     `json:"lazy,omitempty"`
 }
 
+// Values of VariablePresentationHint.Kind.
+const (
+	VariablePresentationHintKindProperty         = "property"
+	VariablePresentationHintKindMethod           = "method"
+	VariablePresentationHintKindClass            = "class"
+	VariablePresentationHintKindData             = "data"
+	VariablePresentationHintKindEvent            = "event"
+	VariablePresentationHintKindBaseClass        = "baseClass"
+	VariablePresentationHintKindInnerClass       = "innerClass"
+	VariablePresentationHintKindInterface        = "interface"
+	VariablePresentationHintKindMostDerivedClass = "mostDerivedClass"
+	VariablePresentationHintKindVirtual          = "virtual"
+	VariablePresentationHintKindDataBreakpoint   = "dataBreakpoint"
+)
+
+// Values of VariablePresentationHint.Attributes.
+const (
+	VariablePresentationHintAttributesStatic            = "static"
+	VariablePresentationHintAttributesConstant          = "constant"
+	VariablePresentationHintAttributesReadOnly          = "readOnly"
+	VariablePresentationHintAttributesRawString         = "rawString"
+	VariablePresentationHintAttributesHasObjectId       = "hasObjectId"
+	VariablePresentationHintAttributesCanHaveObjectId   = "canHaveObjectId"
+	VariablePresentationHintAttributesHasSideEffects    = "hasSideEffects"
+	VariablePresentationHintAttributesHasDataBreakpoint = "hasDataBreakpoint"
+)
+
+// Values of VariablePresentationHint.Visibility.
+const (
+	VariablePresentationHintVisibilityPublic    = "public"
+	VariablePresentationHintVisibilityPrivate   = "private"
+	VariablePresentationHintVisibilityProtected = "protected"
+	VariablePresentationHintVisibilityInternal  = "internal"
+	VariablePresentationHintVisibilityFinal     = "final"
+)
+
 // BreakpointLocation: Properties of a breakpoint location returned from the `breakpointLocations` request.
 type BreakpointLocation struct {
 	Line      int `json:"line"`
@@ -1637,6 +1807,21 @@ type FunctionBreakpoint struct {
 // DataBreakpointAccessType: This enumeration defines all possible access types for data breakpoints.
 type DataBreakpointAccessType string
 
+const (
+	DataBreakpointAccessTypeRead      DataBreakpointAccessType = "read"
+	DataBreakpointAccessTypeWrite     DataBreakpointAccessType = "write"
+	DataBreakpointAccessTypeReadWrite DataBreakpointAccessType = "readWrite"
+)
+
+// IsValid reports whether d is one of the values allowed by the specification.
+func (d DataBreakpointAccessType) IsValid() bool {
+	switch d {
+	case DataBreakpointAccessTypeRead, DataBreakpointAccessTypeWrite, DataBreakpointAccessTypeReadWrite:
+		return true
+	}
+	return false
+}
+
 // DataBreakpoint: Properties of a data breakpoint passed to the `setDataBreakpoints` request.
 type DataBreakpoint struct {
 	DataId       string                   `json:"dataId"`
@@ -1670,6 +1855,21 @@ type Breakpoint struct {
 // SteppingGranularity: The granularity of one 'step' in the stepping requests `next`, `stepIn`, `stepOut`, and `stepBack`.
 type SteppingGranularity string
 
+const (
+	SteppingGranularityStatement   SteppingGranularity = "statement"
+	SteppingGranularityLine        SteppingGranularity = "line"
+	SteppingGranularityInstruction SteppingGranularity = "instruction"
+)
+
+// IsValid reports whether s is one of the values allowed by the specification.
+func (s SteppingGranularity) IsValid() bool {
+	switch s {
+	case SteppingGranularityStatement, SteppingGranularityLine, SteppingGranularityInstruction:
+		return true
+	}
+	return false
+}
+
 // StepInTarget: A `StepInTarget` can be used in the `stepIn` request and determines into which single target the `stepIn` request should step.
 type StepInTarget struct {
 	Id        int    `json:"id"`
@@ -1708,9 +1908,56 @@ type CompletionItem struct {
 // CompletionItemType: Some predefined types for the CompletionItem. Please note that not all clients have specific icons for all of them.
 type CompletionItemType string
 
+const (
+	CompletionItemTypeMethod      CompletionItemType = "method"
+	CompletionItemTypeFunction    CompletionItemType = "function"
+	CompletionItemTypeConstructor CompletionItemType = "constructor"
+	CompletionItemTypeField       CompletionItemType = "field"
+	CompletionItemTypeVariable    CompletionItemType = "variable"
+	CompletionItemTypeClass       CompletionItemType = "class"
+	CompletionItemTypeInterface   CompletionItemType = "interface"
+	CompletionItemTypeModule      CompletionItemType = "module"
+	CompletionItemTypeProperty    CompletionItemType = "property"
+	CompletionItemTypeUnit        CompletionItemType = "unit"
+	CompletionItemTypeValue       CompletionItemType = "value"
+	CompletionItemTypeEnum        CompletionItemType = "enum"
+	CompletionItemTypeKeyword     CompletionItemType = "keyword"
+	CompletionItemTypeSnippet     CompletionItemType = "snippet"
+	CompletionItemTypeText        CompletionItemType = "text"
+	CompletionItemTypeColor       CompletionItemType = "color"
+	CompletionItemTypeFile        CompletionItemType = "file"
+	CompletionItemTypeReference   CompletionItemType = "reference"
+	CompletionItemTypeCustomcolor CompletionItemType = "customcolor"
+)
+
+// IsValid reports whether c is one of the values allowed by the specification.
+func (c CompletionItemType) IsValid() bool {
+	switch c {
+	case CompletionItemTypeMethod, CompletionItemTypeFunction, CompletionItemTypeConstructor, CompletionItemTypeField, CompletionItemTypeVariable, CompletionItemTypeClass, CompletionItemTypeInterface, CompletionItemTypeModule, CompletionItemTypeProperty, CompletionItemTypeUnit, CompletionItemTypeValue, CompletionItemTypeEnum, CompletionItemTypeKeyword, CompletionItemTypeSnippet, CompletionItemTypeText, CompletionItemTypeColor, CompletionItemTypeFile, CompletionItemTypeReference, CompletionItemTypeCustomcolor:
+		return true
+	}
+	return false
+}
+
 // ChecksumAlgorithm: Names of checksum algorithms that may be supported by a debug adapter.
 type ChecksumAlgorithm string
 
+const (
+	ChecksumAlgorithmMD5       ChecksumAlgorithm = "MD5"
+	ChecksumAlgorithmSHA1      ChecksumAlgorithm = "SHA1"
+	ChecksumAlgorithmSHA256    ChecksumAlgorithm = "SHA256"
+	ChecksumAlgorithmTimestamp ChecksumAlgorithm = "timestamp"
+)
+
+// IsValid reports whether c is one of the values allowed by the specification.
+func (c ChecksumAlgorithm) IsValid() bool {
+	switch c {
+	case ChecksumAlgorithmMD5, ChecksumAlgorithmSHA1, ChecksumAlgorithmSHA256, ChecksumAlgorithmTimestamp:
+		return true
+	}
+	return false
+}
+
 // Checksum: The checksum of an item calculated by the specified algorithm.
 type Checksum struct {
 	Algorithm ChecksumAlgorithm `json:"algorithm"`
@@ -1754,6 +2001,22 @@ type ExceptionOptions struct {
 // userUnhandled: breaks if the exception is not handled by user code.
 type ExceptionBreakMode string
 
+const (
+	ExceptionBreakModeNever         ExceptionBreakMode = "never"
+	ExceptionBreakModeAlways        ExceptionBreakMode = "always"
+	ExceptionBreakModeUnhandled     ExceptionBreakMode = "unhandled"
+	ExceptionBreakModeUserUnhandled ExceptionBreakMode = "userUnhandled"
+)
+
+// IsValid reports whether e is one of the values allowed by the specification.
+func (e ExceptionBreakMode) IsValid() bool {
+	switch e {
+	case ExceptionBreakModeNever, ExceptionBreakModeAlways, ExceptionBreakModeUnhandled, ExceptionBreakModeUserUnhandled:
+		return true
+	}
+	return false
+}
+
 // ExceptionPathSegment: An `ExceptionPathSegment` represents a segment in a path that is used to match leafs or nodes in a tree of exceptions.
 // If a segment consists of more than one name, it matches the names provided if `negate` is false or missing, or it matches anything except the names provided if `negate` is true.
 type ExceptionPathSegment struct {
@@ -1787,6 +2050,13 @@ type DisassembledInstruction struct {
 // InvalidatedAreas: Logical areas that can be invalidated by the `invalidated` event.
 type InvalidatedAreas string
 
+const (
+	InvalidatedAreasAll       InvalidatedAreas = "all"
+	InvalidatedAreasStacks    InvalidatedAreas = "stacks"
+	InvalidatedAreasThreads   InvalidatedAreas = "threads"
+	InvalidatedAreasVariables InvalidatedAreas = "variables"
+)
+
 // Mapping of request commands and corresponding struct constructors that
 // can be passed to json.Unmarshal.
 var requestCtor = map[string]messageCtor{
@@ -1915,3 +2185,1589 @@ var eventCtor = map[string]messageCtor{
 	"invalidated":    func() Message { return &InvalidatedEvent{} },
 	"memory":         func() Message { return &MemoryEvent{} },
 }
+
+// Commands of the requests and responses defined by the specification.
+const (
+	CommandCancel                    = "cancel"
+	CommandRunInTerminal             = "runInTerminal"
+	CommandStartDebugging            = "startDebugging"
+	CommandInitialize                = "initialize"
+	CommandConfigurationDone         = "configurationDone"
+	CommandLaunch                    = "launch"
+	CommandAttach                    = "attach"
+	CommandRestart                   = "restart"
+	CommandDisconnect                = "disconnect"
+	CommandTerminate                 = "terminate"
+	CommandBreakpointLocations       = "breakpointLocations"
+	CommandSetBreakpoints            = "setBreakpoints"
+	CommandSetFunctionBreakpoints    = "setFunctionBreakpoints"
+	CommandSetExceptionBreakpoints   = "setExceptionBreakpoints"
+	CommandDataBreakpointInfo        = "dataBreakpointInfo"
+	CommandSetDataBreakpoints        = "setDataBreakpoints"
+	CommandSetInstructionBreakpoints = "setInstructionBreakpoints"
+	CommandContinue                  = "continue"
+	CommandNext                      = "next"
+	CommandStepIn                    = "stepIn"
+	CommandStepOut                   = "stepOut"
+	CommandStepBack                  = "stepBack"
+	CommandReverseContinue           = "reverseContinue"
+	CommandRestartFrame              = "restartFrame"
+	CommandGoto                      = "goto"
+	CommandPause                     = "pause"
+	CommandStackTrace                = "stackTrace"
+	CommandScopes                    = "scopes"
+	CommandVariables                 = "variables"
+	CommandSetVariable               = "setVariable"
+	CommandSource                    = "source"
+	CommandThreads                   = "threads"
+	CommandTerminateThreads          = "terminateThreads"
+	CommandModules                   = "modules"
+	CommandLoadedSources             = "loadedSources"
+	CommandEvaluate                  = "evaluate"
+	CommandSetExpression             = "setExpression"
+	CommandStepInTargets             = "stepInTargets"
+	CommandGotoTargets               = "gotoTargets"
+	CommandCompletions               = "completions"
+	CommandExceptionInfo             = "exceptionInfo"
+	CommandReadMemory                = "readMemory"
+	CommandWriteMemory               = "writeMemory"
+	CommandDisassemble               = "disassemble"
+)
+
+// Events defined by the specification.
+const (
+	EventInitialized    = "initialized"
+	EventStopped        = "stopped"
+	EventContinued      = "continued"
+	EventExited         = "exited"
+	EventTerminated     = "terminated"
+	EventThread         = "thread"
+	EventOutput         = "output"
+	EventBreakpoint     = "breakpoint"
+	EventModule         = "module"
+	EventLoadedSource   = "loadedSource"
+	EventProcess        = "process"
+	EventCapabilities   = "capabilities"
+	EventProgressStart  = "progressStart"
+	EventProgressUpdate = "progressUpdate"
+	EventProgressEnd    = "progressEnd"
+	EventInvalidated    = "invalidated"
+	EventMemory         = "memory"
+)
+
+// Constructors of messages, which leave Seq for the sender, such as a
+// SeqWriter or a Session, to set.
+
+// NewCancelRequest returns a cancel request with arguments.
+func NewCancelRequest(arguments *CancelArguments) *CancelRequest {
+	return &CancelRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandCancel},
+		Arguments: arguments,
+	}
+}
+
+// NewRunInTerminalRequest returns a runInTerminal request with arguments.
+func NewRunInTerminalRequest(arguments RunInTerminalRequestArguments) *RunInTerminalRequest {
+	return &RunInTerminalRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandRunInTerminal},
+		Arguments: arguments,
+	}
+}
+
+// NewStartDebuggingRequest returns a startDebugging request with arguments.
+func NewStartDebuggingRequest(arguments StartDebuggingRequestArguments) *StartDebuggingRequest {
+	return &StartDebuggingRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandStartDebugging},
+		Arguments: arguments,
+	}
+}
+
+// NewInitializeRequest returns an initialize request with arguments.
+func NewInitializeRequest(arguments InitializeRequestArguments) *InitializeRequest {
+	return &InitializeRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandInitialize},
+		Arguments: arguments,
+	}
+}
+
+// NewConfigurationDoneRequest returns a configurationDone request with arguments.
+func NewConfigurationDoneRequest(arguments *ConfigurationDoneArguments) *ConfigurationDoneRequest {
+	return &ConfigurationDoneRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandConfigurationDone},
+		Arguments: arguments,
+	}
+}
+
+// NewLaunchRequest returns a launch request with arguments.
+func NewLaunchRequest(arguments json.RawMessage) *LaunchRequest {
+	return &LaunchRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandLaunch},
+		Arguments: arguments,
+	}
+}
+
+// NewAttachRequest returns an attach request with arguments.
+func NewAttachRequest(arguments json.RawMessage) *AttachRequest {
+	return &AttachRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandAttach},
+		Arguments: arguments,
+	}
+}
+
+// NewRestartRequest returns a restart request with arguments.
+func NewRestartRequest(arguments json.RawMessage) *RestartRequest {
+	return &RestartRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandRestart},
+		Arguments: arguments,
+	}
+}
+
+// NewDisconnectRequest returns a disconnect request with arguments.
+func NewDisconnectRequest(arguments *DisconnectArguments) *DisconnectRequest {
+	return &DisconnectRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandDisconnect},
+		Arguments: arguments,
+	}
+}
+
+// NewTerminateRequest returns a terminate request with arguments.
+func NewTerminateRequest(arguments *TerminateArguments) *TerminateRequest {
+	return &TerminateRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandTerminate},
+		Arguments: arguments,
+	}
+}
+
+// NewBreakpointLocationsRequest returns a breakpointLocations request with arguments.
+func NewBreakpointLocationsRequest(arguments *BreakpointLocationsArguments) *BreakpointLocationsRequest {
+	return &BreakpointLocationsRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandBreakpointLocations},
+		Arguments: arguments,
+	}
+}
+
+// NewSetBreakpointsRequest returns a setBreakpoints request with arguments.
+func NewSetBreakpointsRequest(arguments SetBreakpointsArguments) *SetBreakpointsRequest {
+	return &SetBreakpointsRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandSetBreakpoints},
+		Arguments: arguments,
+	}
+}
+
+// NewSetFunctionBreakpointsRequest returns a setFunctionBreakpoints request with arguments.
+func NewSetFunctionBreakpointsRequest(arguments SetFunctionBreakpointsArguments) *SetFunctionBreakpointsRequest {
+	return &SetFunctionBreakpointsRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandSetFunctionBreakpoints},
+		Arguments: arguments,
+	}
+}
+
+// NewSetExceptionBreakpointsRequest returns a setExceptionBreakpoints request with arguments.
+func NewSetExceptionBreakpointsRequest(arguments SetExceptionBreakpointsArguments) *SetExceptionBreakpointsRequest {
+	return &SetExceptionBreakpointsRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandSetExceptionBreakpoints},
+		Arguments: arguments,
+	}
+}
+
+// NewDataBreakpointInfoRequest returns a dataBreakpointInfo request with arguments.
+func NewDataBreakpointInfoRequest(arguments DataBreakpointInfoArguments) *DataBreakpointInfoRequest {
+	return &DataBreakpointInfoRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandDataBreakpointInfo},
+		Arguments: arguments,
+	}
+}
+
+// NewSetDataBreakpointsRequest returns a setDataBreakpoints request with arguments.
+func NewSetDataBreakpointsRequest(arguments SetDataBreakpointsArguments) *SetDataBreakpointsRequest {
+	return &SetDataBreakpointsRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandSetDataBreakpoints},
+		Arguments: arguments,
+	}
+}
+
+// NewSetInstructionBreakpointsRequest returns a setInstructionBreakpoints request with arguments.
+func NewSetInstructionBreakpointsRequest(arguments SetInstructionBreakpointsArguments) *SetInstructionBreakpointsRequest {
+	return &SetInstructionBreakpointsRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandSetInstructionBreakpoints},
+		Arguments: arguments,
+	}
+}
+
+// NewContinueRequest returns a continue request with arguments.
+func NewContinueRequest(arguments ContinueArguments) *ContinueRequest {
+	return &ContinueRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandContinue},
+		Arguments: arguments,
+	}
+}
+
+// NewNextRequest returns a next request with arguments.
+func NewNextRequest(arguments NextArguments) *NextRequest {
+	return &NextRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandNext},
+		Arguments: arguments,
+	}
+}
+
+// NewStepInRequest returns a stepIn request with arguments.
+func NewStepInRequest(arguments StepInArguments) *StepInRequest {
+	return &StepInRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandStepIn},
+		Arguments: arguments,
+	}
+}
+
+// NewStepOutRequest returns a stepOut request with arguments.
+func NewStepOutRequest(arguments StepOutArguments) *StepOutRequest {
+	return &StepOutRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandStepOut},
+		Arguments: arguments,
+	}
+}
+
+// NewStepBackRequest returns a stepBack request with arguments.
+func NewStepBackRequest(arguments StepBackArguments) *StepBackRequest {
+	return &StepBackRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandStepBack},
+		Arguments: arguments,
+	}
+}
+
+// NewReverseContinueRequest returns a reverseContinue request with arguments.
+func NewReverseContinueRequest(arguments ReverseContinueArguments) *ReverseContinueRequest {
+	return &ReverseContinueRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandReverseContinue},
+		Arguments: arguments,
+	}
+}
+
+// NewRestartFrameRequest returns a restartFrame request with arguments.
+func NewRestartFrameRequest(arguments RestartFrameArguments) *RestartFrameRequest {
+	return &RestartFrameRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandRestartFrame},
+		Arguments: arguments,
+	}
+}
+
+// NewGotoRequest returns a goto request with arguments.
+func NewGotoRequest(arguments GotoArguments) *GotoRequest {
+	return &GotoRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandGoto},
+		Arguments: arguments,
+	}
+}
+
+// NewPauseRequest returns a pause request with arguments.
+func NewPauseRequest(arguments PauseArguments) *PauseRequest {
+	return &PauseRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandPause},
+		Arguments: arguments,
+	}
+}
+
+// NewStackTraceRequest returns a stackTrace request with arguments.
+func NewStackTraceRequest(arguments StackTraceArguments) *StackTraceRequest {
+	return &StackTraceRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandStackTrace},
+		Arguments: arguments,
+	}
+}
+
+// NewScopesRequest returns a scopes request with arguments.
+func NewScopesRequest(arguments ScopesArguments) *ScopesRequest {
+	return &ScopesRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandScopes},
+		Arguments: arguments,
+	}
+}
+
+// NewVariablesRequest returns a variables request with arguments.
+func NewVariablesRequest(arguments VariablesArguments) *VariablesRequest {
+	return &VariablesRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandVariables},
+		Arguments: arguments,
+	}
+}
+
+// NewSetVariableRequest returns a setVariable request with arguments.
+func NewSetVariableRequest(arguments SetVariableArguments) *SetVariableRequest {
+	return &SetVariableRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandSetVariable},
+		Arguments: arguments,
+	}
+}
+
+// NewSourceRequest returns a source request with arguments.
+func NewSourceRequest(arguments SourceArguments) *SourceRequest {
+	return &SourceRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandSource},
+		Arguments: arguments,
+	}
+}
+
+// NewThreadsRequest returns a threads request.
+func NewThreadsRequest() *ThreadsRequest {
+	return &ThreadsRequest{
+		Request: Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandThreads},
+	}
+}
+
+// NewTerminateThreadsRequest returns a terminateThreads request with arguments.
+func NewTerminateThreadsRequest(arguments TerminateThreadsArguments) *TerminateThreadsRequest {
+	return &TerminateThreadsRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandTerminateThreads},
+		Arguments: arguments,
+	}
+}
+
+// NewModulesRequest returns a modules request with arguments.
+func NewModulesRequest(arguments ModulesArguments) *ModulesRequest {
+	return &ModulesRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandModules},
+		Arguments: arguments,
+	}
+}
+
+// NewLoadedSourcesRequest returns a loadedSources request with arguments.
+func NewLoadedSourcesRequest(arguments *LoadedSourcesArguments) *LoadedSourcesRequest {
+	return &LoadedSourcesRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandLoadedSources},
+		Arguments: arguments,
+	}
+}
+
+// NewEvaluateRequest returns an evaluate request with arguments.
+func NewEvaluateRequest(arguments EvaluateArguments) *EvaluateRequest {
+	return &EvaluateRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandEvaluate},
+		Arguments: arguments,
+	}
+}
+
+// NewSetExpressionRequest returns a setExpression request with arguments.
+func NewSetExpressionRequest(arguments SetExpressionArguments) *SetExpressionRequest {
+	return &SetExpressionRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandSetExpression},
+		Arguments: arguments,
+	}
+}
+
+// NewStepInTargetsRequest returns a stepInTargets request with arguments.
+func NewStepInTargetsRequest(arguments StepInTargetsArguments) *StepInTargetsRequest {
+	return &StepInTargetsRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandStepInTargets},
+		Arguments: arguments,
+	}
+}
+
+// NewGotoTargetsRequest returns a gotoTargets request with arguments.
+func NewGotoTargetsRequest(arguments GotoTargetsArguments) *GotoTargetsRequest {
+	return &GotoTargetsRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandGotoTargets},
+		Arguments: arguments,
+	}
+}
+
+// NewCompletionsRequest returns a completions request with arguments.
+func NewCompletionsRequest(arguments CompletionsArguments) *CompletionsRequest {
+	return &CompletionsRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandCompletions},
+		Arguments: arguments,
+	}
+}
+
+// NewExceptionInfoRequest returns an exceptionInfo request with arguments.
+func NewExceptionInfoRequest(arguments ExceptionInfoArguments) *ExceptionInfoRequest {
+	return &ExceptionInfoRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandExceptionInfo},
+		Arguments: arguments,
+	}
+}
+
+// NewReadMemoryRequest returns a readMemory request with arguments.
+func NewReadMemoryRequest(arguments ReadMemoryArguments) *ReadMemoryRequest {
+	return &ReadMemoryRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandReadMemory},
+		Arguments: arguments,
+	}
+}
+
+// NewWriteMemoryRequest returns a writeMemory request with arguments.
+func NewWriteMemoryRequest(arguments WriteMemoryArguments) *WriteMemoryRequest {
+	return &WriteMemoryRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandWriteMemory},
+		Arguments: arguments,
+	}
+}
+
+// NewDisassembleRequest returns a disassemble request with arguments.
+func NewDisassembleRequest(arguments DisassembleArguments) *DisassembleRequest {
+	return &DisassembleRequest{
+		Request:   Request{ProtocolMessage: ProtocolMessage{Type: "request"}, Command: CommandDisassemble},
+		Arguments: arguments,
+	}
+}
+
+// NewCancelResponse returns a successful response to req.
+func NewCancelResponse(req *CancelRequest) *CancelResponse {
+	return &CancelResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandCancel, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewRunInTerminalResponse returns a successful response to req with body.
+func NewRunInTerminalResponse(req *RunInTerminalRequest, body RunInTerminalResponseBody) *RunInTerminalResponse {
+	return &RunInTerminalResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandRunInTerminal, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewStartDebuggingResponse returns a successful response to req.
+func NewStartDebuggingResponse(req *StartDebuggingRequest) *StartDebuggingResponse {
+	return &StartDebuggingResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandStartDebugging, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewInitializeResponse returns a successful response to req with body.
+func NewInitializeResponse(req *InitializeRequest, body Capabilities) *InitializeResponse {
+	return &InitializeResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandInitialize, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewConfigurationDoneResponse returns a successful response to req.
+func NewConfigurationDoneResponse(req *ConfigurationDoneRequest) *ConfigurationDoneResponse {
+	return &ConfigurationDoneResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandConfigurationDone, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewLaunchResponse returns a successful response to req.
+func NewLaunchResponse(req *LaunchRequest) *LaunchResponse {
+	return &LaunchResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandLaunch, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewAttachResponse returns a successful response to req.
+func NewAttachResponse(req *AttachRequest) *AttachResponse {
+	return &AttachResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandAttach, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewRestartResponse returns a successful response to req.
+func NewRestartResponse(req *RestartRequest) *RestartResponse {
+	return &RestartResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandRestart, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewDisconnectResponse returns a successful response to req.
+func NewDisconnectResponse(req *DisconnectRequest) *DisconnectResponse {
+	return &DisconnectResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandDisconnect, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewTerminateResponse returns a successful response to req.
+func NewTerminateResponse(req *TerminateRequest) *TerminateResponse {
+	return &TerminateResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandTerminate, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewBreakpointLocationsResponse returns a successful response to req with body.
+func NewBreakpointLocationsResponse(req *BreakpointLocationsRequest, body BreakpointLocationsResponseBody) *BreakpointLocationsResponse {
+	return &BreakpointLocationsResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandBreakpointLocations, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewSetBreakpointsResponse returns a successful response to req with body.
+func NewSetBreakpointsResponse(req *SetBreakpointsRequest, body SetBreakpointsResponseBody) *SetBreakpointsResponse {
+	return &SetBreakpointsResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandSetBreakpoints, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewSetFunctionBreakpointsResponse returns a successful response to req with body.
+func NewSetFunctionBreakpointsResponse(req *SetFunctionBreakpointsRequest, body SetFunctionBreakpointsResponseBody) *SetFunctionBreakpointsResponse {
+	return &SetFunctionBreakpointsResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandSetFunctionBreakpoints, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewSetExceptionBreakpointsResponse returns a successful response to req with body.
+func NewSetExceptionBreakpointsResponse(req *SetExceptionBreakpointsRequest, body SetExceptionBreakpointsResponseBody) *SetExceptionBreakpointsResponse {
+	return &SetExceptionBreakpointsResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandSetExceptionBreakpoints, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewDataBreakpointInfoResponse returns a successful response to req with body.
+func NewDataBreakpointInfoResponse(req *DataBreakpointInfoRequest, body DataBreakpointInfoResponseBody) *DataBreakpointInfoResponse {
+	return &DataBreakpointInfoResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandDataBreakpointInfo, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewSetDataBreakpointsResponse returns a successful response to req with body.
+func NewSetDataBreakpointsResponse(req *SetDataBreakpointsRequest, body SetDataBreakpointsResponseBody) *SetDataBreakpointsResponse {
+	return &SetDataBreakpointsResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandSetDataBreakpoints, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewSetInstructionBreakpointsResponse returns a successful response to req with body.
+func NewSetInstructionBreakpointsResponse(req *SetInstructionBreakpointsRequest, body SetInstructionBreakpointsResponseBody) *SetInstructionBreakpointsResponse {
+	return &SetInstructionBreakpointsResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandSetInstructionBreakpoints, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewContinueResponse returns a successful response to req with body.
+func NewContinueResponse(req *ContinueRequest, body ContinueResponseBody) *ContinueResponse {
+	return &ContinueResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandContinue, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewNextResponse returns a successful response to req.
+func NewNextResponse(req *NextRequest) *NextResponse {
+	return &NextResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandNext, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewStepInResponse returns a successful response to req.
+func NewStepInResponse(req *StepInRequest) *StepInResponse {
+	return &StepInResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandStepIn, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewStepOutResponse returns a successful response to req.
+func NewStepOutResponse(req *StepOutRequest) *StepOutResponse {
+	return &StepOutResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandStepOut, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewStepBackResponse returns a successful response to req.
+func NewStepBackResponse(req *StepBackRequest) *StepBackResponse {
+	return &StepBackResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandStepBack, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewReverseContinueResponse returns a successful response to req.
+func NewReverseContinueResponse(req *ReverseContinueRequest) *ReverseContinueResponse {
+	return &ReverseContinueResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandReverseContinue, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewRestartFrameResponse returns a successful response to req.
+func NewRestartFrameResponse(req *RestartFrameRequest) *RestartFrameResponse {
+	return &RestartFrameResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandRestartFrame, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewGotoResponse returns a successful response to req.
+func NewGotoResponse(req *GotoRequest) *GotoResponse {
+	return &GotoResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandGoto, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewPauseResponse returns a successful response to req.
+func NewPauseResponse(req *PauseRequest) *PauseResponse {
+	return &PauseResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandPause, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewStackTraceResponse returns a successful response to req with body.
+func NewStackTraceResponse(req *StackTraceRequest, body StackTraceResponseBody) *StackTraceResponse {
+	return &StackTraceResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandStackTrace, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewScopesResponse returns a successful response to req with body.
+func NewScopesResponse(req *ScopesRequest, body ScopesResponseBody) *ScopesResponse {
+	return &ScopesResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandScopes, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewVariablesResponse returns a successful response to req with body.
+func NewVariablesResponse(req *VariablesRequest, body VariablesResponseBody) *VariablesResponse {
+	return &VariablesResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandVariables, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewSetVariableResponse returns a successful response to req with body.
+func NewSetVariableResponse(req *SetVariableRequest, body SetVariableResponseBody) *SetVariableResponse {
+	return &SetVariableResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandSetVariable, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewSourceResponse returns a successful response to req with body.
+func NewSourceResponse(req *SourceRequest, body SourceResponseBody) *SourceResponse {
+	return &SourceResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandSource, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewThreadsResponse returns a successful response to req with body.
+func NewThreadsResponse(req *ThreadsRequest, body ThreadsResponseBody) *ThreadsResponse {
+	return &ThreadsResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandThreads, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewTerminateThreadsResponse returns a successful response to req.
+func NewTerminateThreadsResponse(req *TerminateThreadsRequest) *TerminateThreadsResponse {
+	return &TerminateThreadsResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandTerminateThreads, RequestSeq: req.Seq, Success: true},
+	}
+}
+
+// NewModulesResponse returns a successful response to req with body.
+func NewModulesResponse(req *ModulesRequest, body ModulesResponseBody) *ModulesResponse {
+	return &ModulesResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandModules, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewLoadedSourcesResponse returns a successful response to req with body.
+func NewLoadedSourcesResponse(req *LoadedSourcesRequest, body LoadedSourcesResponseBody) *LoadedSourcesResponse {
+	return &LoadedSourcesResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandLoadedSources, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewEvaluateResponse returns a successful response to req with body.
+func NewEvaluateResponse(req *EvaluateRequest, body EvaluateResponseBody) *EvaluateResponse {
+	return &EvaluateResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandEvaluate, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewSetExpressionResponse returns a successful response to req with body.
+func NewSetExpressionResponse(req *SetExpressionRequest, body SetExpressionResponseBody) *SetExpressionResponse {
+	return &SetExpressionResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandSetExpression, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewStepInTargetsResponse returns a successful response to req with body.
+func NewStepInTargetsResponse(req *StepInTargetsRequest, body StepInTargetsResponseBody) *StepInTargetsResponse {
+	return &StepInTargetsResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandStepInTargets, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewGotoTargetsResponse returns a successful response to req with body.
+func NewGotoTargetsResponse(req *GotoTargetsRequest, body GotoTargetsResponseBody) *GotoTargetsResponse {
+	return &GotoTargetsResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandGotoTargets, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewCompletionsResponse returns a successful response to req with body.
+func NewCompletionsResponse(req *CompletionsRequest, body CompletionsResponseBody) *CompletionsResponse {
+	return &CompletionsResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandCompletions, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewExceptionInfoResponse returns a successful response to req with body.
+func NewExceptionInfoResponse(req *ExceptionInfoRequest, body ExceptionInfoResponseBody) *ExceptionInfoResponse {
+	return &ExceptionInfoResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandExceptionInfo, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewReadMemoryResponse returns a successful response to req with body.
+func NewReadMemoryResponse(req *ReadMemoryRequest, body ReadMemoryResponseBody) *ReadMemoryResponse {
+	return &ReadMemoryResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandReadMemory, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewWriteMemoryResponse returns a successful response to req with body.
+func NewWriteMemoryResponse(req *WriteMemoryRequest, body WriteMemoryResponseBody) *WriteMemoryResponse {
+	return &WriteMemoryResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandWriteMemory, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewDisassembleResponse returns a successful response to req with body.
+func NewDisassembleResponse(req *DisassembleRequest, body DisassembleResponseBody) *DisassembleResponse {
+	return &DisassembleResponse{
+		Response: Response{ProtocolMessage: ProtocolMessage{Type: "response"}, Command: CommandDisassemble, RequestSeq: req.Seq, Success: true},
+		Body:     body,
+	}
+}
+
+// NewInitializedEvent returns an initialized event.
+func NewInitializedEvent() *InitializedEvent {
+	return &InitializedEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventInitialized},
+	}
+}
+
+// NewStoppedEvent returns a stopped event with body.
+func NewStoppedEvent(body StoppedEventBody) *StoppedEvent {
+	return &StoppedEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventStopped},
+		Body:  body,
+	}
+}
+
+// NewContinuedEvent returns a continued event with body.
+func NewContinuedEvent(body ContinuedEventBody) *ContinuedEvent {
+	return &ContinuedEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventContinued},
+		Body:  body,
+	}
+}
+
+// NewExitedEvent returns an exited event with body.
+func NewExitedEvent(body ExitedEventBody) *ExitedEvent {
+	return &ExitedEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventExited},
+		Body:  body,
+	}
+}
+
+// NewTerminatedEvent returns a terminated event with body.
+func NewTerminatedEvent(body TerminatedEventBody) *TerminatedEvent {
+	return &TerminatedEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventTerminated},
+		Body:  body,
+	}
+}
+
+// NewThreadEvent returns a thread event with body.
+func NewThreadEvent(body ThreadEventBody) *ThreadEvent {
+	return &ThreadEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventThread},
+		Body:  body,
+	}
+}
+
+// NewOutputEvent returns an output event with body.
+func NewOutputEvent(body OutputEventBody) *OutputEvent {
+	return &OutputEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventOutput},
+		Body:  body,
+	}
+}
+
+// NewBreakpointEvent returns a breakpoint event with body.
+func NewBreakpointEvent(body BreakpointEventBody) *BreakpointEvent {
+	return &BreakpointEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventBreakpoint},
+		Body:  body,
+	}
+}
+
+// NewModuleEvent returns a module event with body.
+func NewModuleEvent(body ModuleEventBody) *ModuleEvent {
+	return &ModuleEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventModule},
+		Body:  body,
+	}
+}
+
+// NewLoadedSourceEvent returns a loadedSource event with body.
+func NewLoadedSourceEvent(body LoadedSourceEventBody) *LoadedSourceEvent {
+	return &LoadedSourceEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventLoadedSource},
+		Body:  body,
+	}
+}
+
+// NewProcessEvent returns a process event with body.
+func NewProcessEvent(body ProcessEventBody) *ProcessEvent {
+	return &ProcessEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventProcess},
+		Body:  body,
+	}
+}
+
+// NewCapabilitiesEvent returns a capabilities event with body.
+func NewCapabilitiesEvent(body CapabilitiesEventBody) *CapabilitiesEvent {
+	return &CapabilitiesEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventCapabilities},
+		Body:  body,
+	}
+}
+
+// NewProgressStartEvent returns a progressStart event with body.
+func NewProgressStartEvent(body ProgressStartEventBody) *ProgressStartEvent {
+	return &ProgressStartEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventProgressStart},
+		Body:  body,
+	}
+}
+
+// NewProgressUpdateEvent returns a progressUpdate event with body.
+func NewProgressUpdateEvent(body ProgressUpdateEventBody) *ProgressUpdateEvent {
+	return &ProgressUpdateEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventProgressUpdate},
+		Body:  body,
+	}
+}
+
+// NewProgressEndEvent returns a progressEnd event with body.
+func NewProgressEndEvent(body ProgressEndEventBody) *ProgressEndEvent {
+	return &ProgressEndEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventProgressEnd},
+		Body:  body,
+	}
+}
+
+// NewInvalidatedEvent returns an invalidated event with body.
+func NewInvalidatedEvent(body InvalidatedEventBody) *InvalidatedEvent {
+	return &InvalidatedEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventInvalidated},
+		Body:  body,
+	}
+}
+
+// NewMemoryEvent returns a memory event with body.
+func NewMemoryEvent(body MemoryEventBody) *MemoryEvent {
+	return &MemoryEvent{
+		Event: Event{ProtocolMessage: ProtocolMessage{Type: "event"}, Event: EventMemory},
+		Body:  body,
+	}
+}
+
+// Handler handles the requests received by a Session, with one method per
+// request type defined by the specification.
+//
+// Each method is called in its own goroutine, so requests can be processed
+// concurrently. A method returns the response to send back; the Session fills
+// in its Seq, Type, RequestSeq, Command and Success fields, so only the body
+// needs to be populated. A nil response results in an empty successful
+// response, and a non-nil error results in an ErrorResponse.
+//
+// Implementations should embed UnimplementedHandler, so that they only need
+// to implement the requests they support.
+type Handler interface {
+	OnCancelRequest(ctx context.Context, req *CancelRequest) (*CancelResponse, error)
+	OnRunInTerminalRequest(ctx context.Context, req *RunInTerminalRequest) (*RunInTerminalResponse, error)
+	OnStartDebuggingRequest(ctx context.Context, req *StartDebuggingRequest) (*StartDebuggingResponse, error)
+	OnInitializeRequest(ctx context.Context, req *InitializeRequest) (*InitializeResponse, error)
+	OnConfigurationDoneRequest(ctx context.Context, req *ConfigurationDoneRequest) (*ConfigurationDoneResponse, error)
+	OnLaunchRequest(ctx context.Context, req *LaunchRequest) (*LaunchResponse, error)
+	OnAttachRequest(ctx context.Context, req *AttachRequest) (*AttachResponse, error)
+	OnRestartRequest(ctx context.Context, req *RestartRequest) (*RestartResponse, error)
+	OnDisconnectRequest(ctx context.Context, req *DisconnectRequest) (*DisconnectResponse, error)
+	OnTerminateRequest(ctx context.Context, req *TerminateRequest) (*TerminateResponse, error)
+	OnBreakpointLocationsRequest(ctx context.Context, req *BreakpointLocationsRequest) (*BreakpointLocationsResponse, error)
+	OnSetBreakpointsRequest(ctx context.Context, req *SetBreakpointsRequest) (*SetBreakpointsResponse, error)
+	OnSetFunctionBreakpointsRequest(ctx context.Context, req *SetFunctionBreakpointsRequest) (*SetFunctionBreakpointsResponse, error)
+	OnSetExceptionBreakpointsRequest(ctx context.Context, req *SetExceptionBreakpointsRequest) (*SetExceptionBreakpointsResponse, error)
+	OnDataBreakpointInfoRequest(ctx context.Context, req *DataBreakpointInfoRequest) (*DataBreakpointInfoResponse, error)
+	OnSetDataBreakpointsRequest(ctx context.Context, req *SetDataBreakpointsRequest) (*SetDataBreakpointsResponse, error)
+	OnSetInstructionBreakpointsRequest(ctx context.Context, req *SetInstructionBreakpointsRequest) (*SetInstructionBreakpointsResponse, error)
+	OnContinueRequest(ctx context.Context, req *ContinueRequest) (*ContinueResponse, error)
+	OnNextRequest(ctx context.Context, req *NextRequest) (*NextResponse, error)
+	OnStepInRequest(ctx context.Context, req *StepInRequest) (*StepInResponse, error)
+	OnStepOutRequest(ctx context.Context, req *StepOutRequest) (*StepOutResponse, error)
+	OnStepBackRequest(ctx context.Context, req *StepBackRequest) (*StepBackResponse, error)
+	OnReverseContinueRequest(ctx context.Context, req *ReverseContinueRequest) (*ReverseContinueResponse, error)
+	OnRestartFrameRequest(ctx context.Context, req *RestartFrameRequest) (*RestartFrameResponse, error)
+	OnGotoRequest(ctx context.Context, req *GotoRequest) (*GotoResponse, error)
+	OnPauseRequest(ctx context.Context, req *PauseRequest) (*PauseResponse, error)
+	OnStackTraceRequest(ctx context.Context, req *StackTraceRequest) (*StackTraceResponse, error)
+	OnScopesRequest(ctx context.Context, req *ScopesRequest) (*ScopesResponse, error)
+	OnVariablesRequest(ctx context.Context, req *VariablesRequest) (*VariablesResponse, error)
+	OnSetVariableRequest(ctx context.Context, req *SetVariableRequest) (*SetVariableResponse, error)
+	OnSourceRequest(ctx context.Context, req *SourceRequest) (*SourceResponse, error)
+	OnThreadsRequest(ctx context.Context, req *ThreadsRequest) (*ThreadsResponse, error)
+	OnTerminateThreadsRequest(ctx context.Context, req *TerminateThreadsRequest) (*TerminateThreadsResponse, error)
+	OnModulesRequest(ctx context.Context, req *ModulesRequest) (*ModulesResponse, error)
+	OnLoadedSourcesRequest(ctx context.Context, req *LoadedSourcesRequest) (*LoadedSourcesResponse, error)
+	OnEvaluateRequest(ctx context.Context, req *EvaluateRequest) (*EvaluateResponse, error)
+	OnSetExpressionRequest(ctx context.Context, req *SetExpressionRequest) (*SetExpressionResponse, error)
+	OnStepInTargetsRequest(ctx context.Context, req *StepInTargetsRequest) (*StepInTargetsResponse, error)
+	OnGotoTargetsRequest(ctx context.Context, req *GotoTargetsRequest) (*GotoTargetsResponse, error)
+	OnCompletionsRequest(ctx context.Context, req *CompletionsRequest) (*CompletionsResponse, error)
+	OnExceptionInfoRequest(ctx context.Context, req *ExceptionInfoRequest) (*ExceptionInfoResponse, error)
+	OnReadMemoryRequest(ctx context.Context, req *ReadMemoryRequest) (*ReadMemoryResponse, error)
+	OnWriteMemoryRequest(ctx context.Context, req *WriteMemoryRequest) (*WriteMemoryResponse, error)
+	OnDisassembleRequest(ctx context.Context, req *DisassembleRequest) (*DisassembleResponse, error)
+}
+
+func (UnimplementedHandler) OnCancelRequest(ctx context.Context, req *CancelRequest) (*CancelResponse, error) {
+	return nil, nil
+}
+
+func (UnimplementedHandler) OnRunInTerminalRequest(ctx context.Context, req *RunInTerminalRequest) (*RunInTerminalResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnStartDebuggingRequest(ctx context.Context, req *StartDebuggingRequest) (*StartDebuggingResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnInitializeRequest(ctx context.Context, req *InitializeRequest) (*InitializeResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnConfigurationDoneRequest(ctx context.Context, req *ConfigurationDoneRequest) (*ConfigurationDoneResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnLaunchRequest(ctx context.Context, req *LaunchRequest) (*LaunchResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnAttachRequest(ctx context.Context, req *AttachRequest) (*AttachResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnRestartRequest(ctx context.Context, req *RestartRequest) (*RestartResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnDisconnectRequest(ctx context.Context, req *DisconnectRequest) (*DisconnectResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnTerminateRequest(ctx context.Context, req *TerminateRequest) (*TerminateResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnBreakpointLocationsRequest(ctx context.Context, req *BreakpointLocationsRequest) (*BreakpointLocationsResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnSetBreakpointsRequest(ctx context.Context, req *SetBreakpointsRequest) (*SetBreakpointsResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnSetFunctionBreakpointsRequest(ctx context.Context, req *SetFunctionBreakpointsRequest) (*SetFunctionBreakpointsResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnSetExceptionBreakpointsRequest(ctx context.Context, req *SetExceptionBreakpointsRequest) (*SetExceptionBreakpointsResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnDataBreakpointInfoRequest(ctx context.Context, req *DataBreakpointInfoRequest) (*DataBreakpointInfoResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnSetDataBreakpointsRequest(ctx context.Context, req *SetDataBreakpointsRequest) (*SetDataBreakpointsResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnSetInstructionBreakpointsRequest(ctx context.Context, req *SetInstructionBreakpointsRequest) (*SetInstructionBreakpointsResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnContinueRequest(ctx context.Context, req *ContinueRequest) (*ContinueResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnNextRequest(ctx context.Context, req *NextRequest) (*NextResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnStepInRequest(ctx context.Context, req *StepInRequest) (*StepInResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnStepOutRequest(ctx context.Context, req *StepOutRequest) (*StepOutResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnStepBackRequest(ctx context.Context, req *StepBackRequest) (*StepBackResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnReverseContinueRequest(ctx context.Context, req *ReverseContinueRequest) (*ReverseContinueResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnRestartFrameRequest(ctx context.Context, req *RestartFrameRequest) (*RestartFrameResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnGotoRequest(ctx context.Context, req *GotoRequest) (*GotoResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnPauseRequest(ctx context.Context, req *PauseRequest) (*PauseResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnStackTraceRequest(ctx context.Context, req *StackTraceRequest) (*StackTraceResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnScopesRequest(ctx context.Context, req *ScopesRequest) (*ScopesResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnVariablesRequest(ctx context.Context, req *VariablesRequest) (*VariablesResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnSetVariableRequest(ctx context.Context, req *SetVariableRequest) (*SetVariableResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnSourceRequest(ctx context.Context, req *SourceRequest) (*SourceResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnThreadsRequest(ctx context.Context, req *ThreadsRequest) (*ThreadsResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnTerminateThreadsRequest(ctx context.Context, req *TerminateThreadsRequest) (*TerminateThreadsResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnModulesRequest(ctx context.Context, req *ModulesRequest) (*ModulesResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnLoadedSourcesRequest(ctx context.Context, req *LoadedSourcesRequest) (*LoadedSourcesResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnEvaluateRequest(ctx context.Context, req *EvaluateRequest) (*EvaluateResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnSetExpressionRequest(ctx context.Context, req *SetExpressionRequest) (*SetExpressionResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnStepInTargetsRequest(ctx context.Context, req *StepInTargetsRequest) (*StepInTargetsResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnGotoTargetsRequest(ctx context.Context, req *GotoTargetsRequest) (*GotoTargetsResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnCompletionsRequest(ctx context.Context, req *CompletionsRequest) (*CompletionsResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnExceptionInfoRequest(ctx context.Context, req *ExceptionInfoRequest) (*ExceptionInfoResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnReadMemoryRequest(ctx context.Context, req *ReadMemoryRequest) (*ReadMemoryResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnWriteMemoryRequest(ctx context.Context, req *WriteMemoryRequest) (*WriteMemoryResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (UnimplementedHandler) OnDisassembleRequest(ctx context.Context, req *DisassembleRequest) (*DisassembleResponse, error) {
+	return nil, unsupported(req)
+}
+
+func (f HandlerFunc) OnCancelRequest(ctx context.Context, req *CancelRequest) (*CancelResponse, error) {
+	return callHandlerFunc[CancelResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnRunInTerminalRequest(ctx context.Context, req *RunInTerminalRequest) (*RunInTerminalResponse, error) {
+	return callHandlerFunc[RunInTerminalResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnStartDebuggingRequest(ctx context.Context, req *StartDebuggingRequest) (*StartDebuggingResponse, error) {
+	return callHandlerFunc[StartDebuggingResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnInitializeRequest(ctx context.Context, req *InitializeRequest) (*InitializeResponse, error) {
+	return callHandlerFunc[InitializeResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnConfigurationDoneRequest(ctx context.Context, req *ConfigurationDoneRequest) (*ConfigurationDoneResponse, error) {
+	return callHandlerFunc[ConfigurationDoneResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnLaunchRequest(ctx context.Context, req *LaunchRequest) (*LaunchResponse, error) {
+	return callHandlerFunc[LaunchResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnAttachRequest(ctx context.Context, req *AttachRequest) (*AttachResponse, error) {
+	return callHandlerFunc[AttachResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnRestartRequest(ctx context.Context, req *RestartRequest) (*RestartResponse, error) {
+	return callHandlerFunc[RestartResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnDisconnectRequest(ctx context.Context, req *DisconnectRequest) (*DisconnectResponse, error) {
+	return callHandlerFunc[DisconnectResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnTerminateRequest(ctx context.Context, req *TerminateRequest) (*TerminateResponse, error) {
+	return callHandlerFunc[TerminateResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnBreakpointLocationsRequest(ctx context.Context, req *BreakpointLocationsRequest) (*BreakpointLocationsResponse, error) {
+	return callHandlerFunc[BreakpointLocationsResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnSetBreakpointsRequest(ctx context.Context, req *SetBreakpointsRequest) (*SetBreakpointsResponse, error) {
+	return callHandlerFunc[SetBreakpointsResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnSetFunctionBreakpointsRequest(ctx context.Context, req *SetFunctionBreakpointsRequest) (*SetFunctionBreakpointsResponse, error) {
+	return callHandlerFunc[SetFunctionBreakpointsResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnSetExceptionBreakpointsRequest(ctx context.Context, req *SetExceptionBreakpointsRequest) (*SetExceptionBreakpointsResponse, error) {
+	return callHandlerFunc[SetExceptionBreakpointsResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnDataBreakpointInfoRequest(ctx context.Context, req *DataBreakpointInfoRequest) (*DataBreakpointInfoResponse, error) {
+	return callHandlerFunc[DataBreakpointInfoResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnSetDataBreakpointsRequest(ctx context.Context, req *SetDataBreakpointsRequest) (*SetDataBreakpointsResponse, error) {
+	return callHandlerFunc[SetDataBreakpointsResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnSetInstructionBreakpointsRequest(ctx context.Context, req *SetInstructionBreakpointsRequest) (*SetInstructionBreakpointsResponse, error) {
+	return callHandlerFunc[SetInstructionBreakpointsResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnContinueRequest(ctx context.Context, req *ContinueRequest) (*ContinueResponse, error) {
+	return callHandlerFunc[ContinueResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnNextRequest(ctx context.Context, req *NextRequest) (*NextResponse, error) {
+	return callHandlerFunc[NextResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnStepInRequest(ctx context.Context, req *StepInRequest) (*StepInResponse, error) {
+	return callHandlerFunc[StepInResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnStepOutRequest(ctx context.Context, req *StepOutRequest) (*StepOutResponse, error) {
+	return callHandlerFunc[StepOutResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnStepBackRequest(ctx context.Context, req *StepBackRequest) (*StepBackResponse, error) {
+	return callHandlerFunc[StepBackResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnReverseContinueRequest(ctx context.Context, req *ReverseContinueRequest) (*ReverseContinueResponse, error) {
+	return callHandlerFunc[ReverseContinueResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnRestartFrameRequest(ctx context.Context, req *RestartFrameRequest) (*RestartFrameResponse, error) {
+	return callHandlerFunc[RestartFrameResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnGotoRequest(ctx context.Context, req *GotoRequest) (*GotoResponse, error) {
+	return callHandlerFunc[GotoResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnPauseRequest(ctx context.Context, req *PauseRequest) (*PauseResponse, error) {
+	return callHandlerFunc[PauseResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnStackTraceRequest(ctx context.Context, req *StackTraceRequest) (*StackTraceResponse, error) {
+	return callHandlerFunc[StackTraceResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnScopesRequest(ctx context.Context, req *ScopesRequest) (*ScopesResponse, error) {
+	return callHandlerFunc[ScopesResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnVariablesRequest(ctx context.Context, req *VariablesRequest) (*VariablesResponse, error) {
+	return callHandlerFunc[VariablesResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnSetVariableRequest(ctx context.Context, req *SetVariableRequest) (*SetVariableResponse, error) {
+	return callHandlerFunc[SetVariableResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnSourceRequest(ctx context.Context, req *SourceRequest) (*SourceResponse, error) {
+	return callHandlerFunc[SourceResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnThreadsRequest(ctx context.Context, req *ThreadsRequest) (*ThreadsResponse, error) {
+	return callHandlerFunc[ThreadsResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnTerminateThreadsRequest(ctx context.Context, req *TerminateThreadsRequest) (*TerminateThreadsResponse, error) {
+	return callHandlerFunc[TerminateThreadsResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnModulesRequest(ctx context.Context, req *ModulesRequest) (*ModulesResponse, error) {
+	return callHandlerFunc[ModulesResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnLoadedSourcesRequest(ctx context.Context, req *LoadedSourcesRequest) (*LoadedSourcesResponse, error) {
+	return callHandlerFunc[LoadedSourcesResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnEvaluateRequest(ctx context.Context, req *EvaluateRequest) (*EvaluateResponse, error) {
+	return callHandlerFunc[EvaluateResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnSetExpressionRequest(ctx context.Context, req *SetExpressionRequest) (*SetExpressionResponse, error) {
+	return callHandlerFunc[SetExpressionResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnStepInTargetsRequest(ctx context.Context, req *StepInTargetsRequest) (*StepInTargetsResponse, error) {
+	return callHandlerFunc[StepInTargetsResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnGotoTargetsRequest(ctx context.Context, req *GotoTargetsRequest) (*GotoTargetsResponse, error) {
+	return callHandlerFunc[GotoTargetsResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnCompletionsRequest(ctx context.Context, req *CompletionsRequest) (*CompletionsResponse, error) {
+	return callHandlerFunc[CompletionsResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnExceptionInfoRequest(ctx context.Context, req *ExceptionInfoRequest) (*ExceptionInfoResponse, error) {
+	return callHandlerFunc[ExceptionInfoResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnReadMemoryRequest(ctx context.Context, req *ReadMemoryRequest) (*ReadMemoryResponse, error) {
+	return callHandlerFunc[ReadMemoryResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnWriteMemoryRequest(ctx context.Context, req *WriteMemoryRequest) (*WriteMemoryResponse, error) {
+	return callHandlerFunc[WriteMemoryResponse](ctx, f, req)
+}
+
+func (f HandlerFunc) OnDisassembleRequest(ctx context.Context, req *DisassembleRequest) (*DisassembleResponse, error) {
+	return callHandlerFunc[DisassembleResponse](ctx, f, req)
+}
+
+// dispatchRequest calls the method of h that handles req and returns the
+// resulting response. Requests that are not defined by the specification
+// are rejected with ErrUnsupportedRequest.
+func dispatchRequest(ctx context.Context, h Handler, req RequestMessage) (ResponseMessage, error) {
+	switch req := req.(type) {
+	case *CancelRequest:
+		return handle(ctx, req, h.OnCancelRequest)
+	case *RunInTerminalRequest:
+		return handle(ctx, req, h.OnRunInTerminalRequest)
+	case *StartDebuggingRequest:
+		return handle(ctx, req, h.OnStartDebuggingRequest)
+	case *InitializeRequest:
+		return handle(ctx, req, h.OnInitializeRequest)
+	case *ConfigurationDoneRequest:
+		return handle(ctx, req, h.OnConfigurationDoneRequest)
+	case *LaunchRequest:
+		return handle(ctx, req, h.OnLaunchRequest)
+	case *AttachRequest:
+		return handle(ctx, req, h.OnAttachRequest)
+	case *RestartRequest:
+		return handle(ctx, req, h.OnRestartRequest)
+	case *DisconnectRequest:
+		return handle(ctx, req, h.OnDisconnectRequest)
+	case *TerminateRequest:
+		return handle(ctx, req, h.OnTerminateRequest)
+	case *BreakpointLocationsRequest:
+		return handle(ctx, req, h.OnBreakpointLocationsRequest)
+	case *SetBreakpointsRequest:
+		return handle(ctx, req, h.OnSetBreakpointsRequest)
+	case *SetFunctionBreakpointsRequest:
+		return handle(ctx, req, h.OnSetFunctionBreakpointsRequest)
+	case *SetExceptionBreakpointsRequest:
+		return handle(ctx, req, h.OnSetExceptionBreakpointsRequest)
+	case *DataBreakpointInfoRequest:
+		return handle(ctx, req, h.OnDataBreakpointInfoRequest)
+	case *SetDataBreakpointsRequest:
+		return handle(ctx, req, h.OnSetDataBreakpointsRequest)
+	case *SetInstructionBreakpointsRequest:
+		return handle(ctx, req, h.OnSetInstructionBreakpointsRequest)
+	case *ContinueRequest:
+		return handle(ctx, req, h.OnContinueRequest)
+	case *NextRequest:
+		return handle(ctx, req, h.OnNextRequest)
+	case *StepInRequest:
+		return handle(ctx, req, h.OnStepInRequest)
+	case *StepOutRequest:
+		return handle(ctx, req, h.OnStepOutRequest)
+	case *StepBackRequest:
+		return handle(ctx, req, h.OnStepBackRequest)
+	case *ReverseContinueRequest:
+		return handle(ctx, req, h.OnReverseContinueRequest)
+	case *RestartFrameRequest:
+		return handle(ctx, req, h.OnRestartFrameRequest)
+	case *GotoRequest:
+		return handle(ctx, req, h.OnGotoRequest)
+	case *PauseRequest:
+		return handle(ctx, req, h.OnPauseRequest)
+	case *StackTraceRequest:
+		return handle(ctx, req, h.OnStackTraceRequest)
+	case *ScopesRequest:
+		return handle(ctx, req, h.OnScopesRequest)
+	case *VariablesRequest:
+		return handle(ctx, req, h.OnVariablesRequest)
+	case *SetVariableRequest:
+		return handle(ctx, req, h.OnSetVariableRequest)
+	case *SourceRequest:
+		return handle(ctx, req, h.OnSourceRequest)
+	case *ThreadsRequest:
+		return handle(ctx, req, h.OnThreadsRequest)
+	case *TerminateThreadsRequest:
+		return handle(ctx, req, h.OnTerminateThreadsRequest)
+	case *ModulesRequest:
+		return handle(ctx, req, h.OnModulesRequest)
+	case *LoadedSourcesRequest:
+		return handle(ctx, req, h.OnLoadedSourcesRequest)
+	case *EvaluateRequest:
+		return handle(ctx, req, h.OnEvaluateRequest)
+	case *SetExpressionRequest:
+		return handle(ctx, req, h.OnSetExpressionRequest)
+	case *StepInTargetsRequest:
+		return handle(ctx, req, h.OnStepInTargetsRequest)
+	case *GotoTargetsRequest:
+		return handle(ctx, req, h.OnGotoTargetsRequest)
+	case *CompletionsRequest:
+		return handle(ctx, req, h.OnCompletionsRequest)
+	case *ExceptionInfoRequest:
+		return handle(ctx, req, h.OnExceptionInfoRequest)
+	case *ReadMemoryRequest:
+		return handle(ctx, req, h.OnReadMemoryRequest)
+	case *WriteMemoryRequest:
+		return handle(ctx, req, h.OnWriteMemoryRequest)
+	case *DisassembleRequest:
+		return handle(ctx, req, h.OnDisassembleRequest)
+	default:
+		return nil, unsupported(req)
+	}
+}
+
+// Mapping of type names and the JSON names of the properties of the type
+// that are required by the specification.
+var requiredProperties = map[string][]string{
+	"ProtocolMessage":                       {"seq", "type"},
+	"Request":                               {"type", "command"},
+	"Event":                                 {"type", "event"},
+	"Response":                              {"type", "request_seq", "success", "command"},
+	"ErrorResponse":                         {"body"},
+	"CancelRequest":                         {"command"},
+	"InitializedEvent":                      {"event"},
+	"StoppedEvent":                          {"event", "body"},
+	"StoppedEventBody":                      {"reason"},
+	"ContinuedEvent":                        {"event", "body"},
+	"ContinuedEventBody":                    {"threadId"},
+	"ExitedEvent":                           {"event", "body"},
+	"ExitedEventBody":                       {"exitCode"},
+	"TerminatedEvent":                       {"event"},
+	"ThreadEvent":                           {"event", "body"},
+	"ThreadEventBody":                       {"reason", "threadId"},
+	"OutputEvent":                           {"event", "body"},
+	"OutputEventBody":                       {"output"},
+	"BreakpointEvent":                       {"event", "body"},
+	"BreakpointEventBody":                   {"reason", "breakpoint"},
+	"ModuleEvent":                           {"event", "body"},
+	"ModuleEventBody":                       {"reason", "module"},
+	"LoadedSourceEvent":                     {"event", "body"},
+	"LoadedSourceEventBody":                 {"reason", "source"},
+	"ProcessEvent":                          {"event", "body"},
+	"ProcessEventBody":                      {"name"},
+	"CapabilitiesEvent":                     {"event", "body"},
+	"CapabilitiesEventBody":                 {"capabilities"},
+	"ProgressStartEvent":                    {"event", "body"},
+	"ProgressStartEventBody":                {"progressId", "title"},
+	"ProgressUpdateEvent":                   {"event", "body"},
+	"ProgressUpdateEventBody":               {"progressId"},
+	"ProgressEndEvent":                      {"event", "body"},
+	"ProgressEndEventBody":                  {"progressId"},
+	"InvalidatedEvent":                      {"event", "body"},
+	"MemoryEvent":                           {"event", "body"},
+	"MemoryEventBody":                       {"memoryReference", "offset", "count"},
+	"RunInTerminalRequest":                  {"command", "arguments"},
+	"RunInTerminalRequestArguments":         {"args", "cwd"},
+	"RunInTerminalResponse":                 {"body"},
+	"StartDebuggingRequest":                 {"command", "arguments"},
+	"StartDebuggingRequestArguments":        {"configuration", "request"},
+	"InitializeRequest":                     {"command", "arguments"},
+	"InitializeRequestArguments":            {"adapterID"},
+	"ConfigurationDoneRequest":              {"command"},
+	"LaunchRequest":                         {"command", "arguments"},
+	"AttachRequest":                         {"command", "arguments"},
+	"RestartRequest":                        {"command"},
+	"DisconnectRequest":                     {"command"},
+	"TerminateRequest":                      {"command"},
+	"BreakpointLocationsRequest":            {"command"},
+	"BreakpointLocationsArguments":          {"source", "line"},
+	"BreakpointLocationsResponse":           {"body"},
+	"BreakpointLocationsResponseBody":       {"breakpoints"},
+	"SetBreakpointsRequest":                 {"command", "arguments"},
+	"SetBreakpointsArguments":               {"source"},
+	"SetBreakpointsResponse":                {"body"},
+	"SetBreakpointsResponseBody":            {"breakpoints"},
+	"SetFunctionBreakpointsRequest":         {"command", "arguments"},
+	"SetFunctionBreakpointsArguments":       {"breakpoints"},
+	"SetFunctionBreakpointsResponse":        {"body"},
+	"SetFunctionBreakpointsResponseBody":    {"breakpoints"},
+	"SetExceptionBreakpointsRequest":        {"command", "arguments"},
+	"SetExceptionBreakpointsArguments":      {"filters"},
+	"DataBreakpointInfoRequest":             {"command", "arguments"},
+	"DataBreakpointInfoArguments":           {"name"},
+	"DataBreakpointInfoResponse":            {"body"},
+	"DataBreakpointInfoResponseBody":        {"dataId", "description"},
+	"SetDataBreakpointsRequest":             {"command", "arguments"},
+	"SetDataBreakpointsArguments":           {"breakpoints"},
+	"SetDataBreakpointsResponse":            {"body"},
+	"SetDataBreakpointsResponseBody":        {"breakpoints"},
+	"SetInstructionBreakpointsRequest":      {"command", "arguments"},
+	"SetInstructionBreakpointsArguments":    {"breakpoints"},
+	"SetInstructionBreakpointsResponse":     {"body"},
+	"SetInstructionBreakpointsResponseBody": {"breakpoints"},
+	"ContinueRequest":                       {"command", "arguments"},
+	"ContinueArguments":                     {"threadId"},
+	"ContinueResponse":                      {"body"},
+	"NextRequest":                           {"command", "arguments"},
+	"NextArguments":                         {"threadId"},
+	"StepInRequest":                         {"command", "arguments"},
+	"StepInArguments":                       {"threadId"},
+	"StepOutRequest":                        {"command", "arguments"},
+	"StepOutArguments":                      {"threadId"},
+	"StepBackRequest":                       {"command", "arguments"},
+	"StepBackArguments":                     {"threadId"},
+	"ReverseContinueRequest":                {"command", "arguments"},
+	"ReverseContinueArguments":              {"threadId"},
+	"RestartFrameRequest":                   {"command", "arguments"},
+	"RestartFrameArguments":                 {"frameId"},
+	"GotoRequest":                           {"command", "arguments"},
+	"GotoArguments":                         {"threadId", "targetId"},
+	"PauseRequest":                          {"command", "arguments"},
+	"PauseArguments":                        {"threadId"},
+	"StackTraceRequest":                     {"command", "arguments"},
+	"StackTraceArguments":                   {"threadId"},
+	"StackTraceResponse":                    {"body"},
+	"StackTraceResponseBody":                {"stackFrames"},
+	"ScopesRequest":                         {"command", "arguments"},
+	"ScopesArguments":                       {"frameId"},
+	"ScopesResponse":                        {"body"},
+	"ScopesResponseBody":                    {"scopes"},
+	"VariablesRequest":                      {"command", "arguments"},
+	"VariablesArguments":                    {"variablesReference"},
+	"VariablesResponse":                     {"body"},
+	"VariablesResponseBody":                 {"variables"},
+	"SetVariableRequest":                    {"command", "arguments"},
+	"SetVariableArguments":                  {"variablesReference", "name", "value"},
+	"SetVariableResponse":                   {"body"},
+	"SetVariableResponseBody":               {"value"},
+	"SourceRequest":                         {"command", "arguments"},
+	"SourceArguments":                       {"sourceReference"},
+	"SourceResponse":                        {"body"},
+	"SourceResponseBody":                    {"content"},
+	"ThreadsRequest":                        {"command"},
+	"ThreadsResponse":                       {"body"},
+	"ThreadsResponseBody":                   {"threads"},
+	"TerminateThreadsRequest":               {"command", "arguments"},
+	"ModulesRequest":                        {"command", "arguments"},
+	"ModulesResponse":                       {"body"},
+	"ModulesResponseBody":                   {"modules"},
+	"LoadedSourcesRequest":                  {"command"},
+	"LoadedSourcesResponse":                 {"body"},
+	"LoadedSourcesResponseBody":             {"sources"},
+	"EvaluateRequest":                       {"command", "arguments"},
+	"EvaluateArguments":                     {"expression"},
+	"EvaluateResponse":                      {"body"},
+	"EvaluateResponseBody":                  {"result", "variablesReference"},
+	"SetExpressionRequest":                  {"command", "arguments"},
+	"SetExpressionArguments":                {"expression", "value"},
+	"SetExpressionResponse":                 {"body"},
+	"SetExpressionResponseBody":             {"value"},
+	"StepInTargetsRequest":                  {"command", "arguments"},
+	"StepInTargetsArguments":                {"frameId"},
+	"StepInTargetsResponse":                 {"body"},
+	"StepInTargetsResponseBody":             {"targets"},
+	"GotoTargetsRequest":                    {"command", "arguments"},
+	"GotoTargetsArguments":                  {"source", "line"},
+	"GotoTargetsResponse":                   {"body"},
+	"GotoTargetsResponseBody":               {"targets"},
+	"CompletionsRequest":                    {"command", "arguments"},
+	"CompletionsArguments":                  {"text", "column"},
+	"CompletionsResponse":                   {"body"},
+	"CompletionsResponseBody":               {"targets"},
+	"ExceptionInfoRequest":                  {"command", "arguments"},
+	"ExceptionInfoArguments":                {"threadId"},
+	"ExceptionInfoResponse":                 {"body"},
+	"ExceptionInfoResponseBody":             {"exceptionId", "breakMode"},
+	"ReadMemoryRequest":                     {"command", "arguments"},
+	"ReadMemoryArguments":                   {"memoryReference", "count"},
+	"ReadMemoryResponseBody":                {"address"},
+	"WriteMemoryRequest":                    {"command", "arguments"},
+	"WriteMemoryArguments":                  {"memoryReference", "data"},
+	"DisassembleRequest":                    {"command", "arguments"},
+	"DisassembleArguments":                  {"memoryReference", "instructionCount"},
+	"DisassembleResponseBody":               {"instructions"},
+	"ExceptionBreakpointsFilter":            {"filter", "label"},
+	"ErrorMessage":                          {"id", "format"},
+	"Module":                                {"id", "name"},
+	"ColumnDescriptor":                      {"attributeName", "label"},
+	"ModulesViewDescriptor":                 {"columns"},
+	"Thread":                                {"id", "name"},
+	"StackFrame":                            {"id", "name", "line", "column"},
+	"Scope":                                 {"name", "variablesReference", "expensive"},
+	"Variable":                              {"name", "value", "variablesReference"},
+	"BreakpointLocation":                    {"line"},
+	"SourceBreakpoint":                      {"line"},
+	"FunctionBreakpoint":                    {"name"},
+	"DataBreakpoint":                        {"dataId"},
+	"InstructionBreakpoint":                 {"instructionReference"},
+	"Breakpoint":                            {"verified"},
+	"StepInTarget":                          {"id", "label"},
+	"GotoTarget":                            {"id", "label", "line"},
+	"CompletionItem":                        {"label"},
+	"Checksum":                              {"algorithm", "checksum"},
+	"ExceptionFilterOptions":                {"filterId"},
+	"ExceptionOptions":                      {"breakMode"},
+	"ExceptionPathSegment":                  {"names"},
+	"DisassembledInstruction":               {"address", "instruction"},
+}
@@ -112,3 +112,62 @@ func TestLaunchAttachRequestInterface(t *testing.T) {
 		t.Errorf(`got lfoo=%v afoo=%v, want "foobar", {"foo":"bar"}`, lfoo, afoo)
 	}
 }
+
+func TestEnumIsValid(t *testing.T) {
+	tests := []struct {
+		enum interface{ IsValid() bool }
+		want bool
+	}{
+		{SteppingGranularityInstruction, true},
+		{SteppingGranularity("expression"), false},
+		{ChecksumAlgorithmSHA256, true},
+		{ChecksumAlgorithm("sha256"), false},
+		{ExceptionBreakModeUserUnhandled, true},
+		{ExceptionBreakMode(""), false},
+	}
+	for _, test := range tests {
+		if got := test.enum.IsValid(); got != test.want {
+			t.Errorf("%T(%q).IsValid() = %v, want %v", test.enum, test.enum, got, test.want)
+		}
+	}
+
+	// The constants of enum properties are untyped, and the properties are
+	// strings, so open enums accept values beyond those of the
+	// specification.
+	var stopped StoppedEventBody
+	if err := json.Unmarshal([]byte(`{"reason":"function breakpoint"}`), &stopped); err != nil {
+		t.Fatal(err)
+	}
+	if stopped.Reason != StoppedEventBodyReasonFunctionBreakpoint {
+		t.Errorf("got reason %q, want %q", stopped.Reason, StoppedEventBodyReasonFunctionBreakpoint)
+	}
+}
+
+func TestMessageConstructors(t *testing.T) {
+	req := NewSetBreakpointsRequest(SetBreakpointsArguments{Source: Source{Path: "a.go"}})
+	req.Seq = 7
+	resp := NewSetBreakpointsResponse(req, SetBreakpointsResponseBody{Breakpoints: []Breakpoint{{Verified: true}}})
+	event := NewStoppedEvent(StoppedEventBody{Reason: StoppedEventBodyReasonStep})
+
+	for _, test := range []struct {
+		m    Message
+		want string
+	}{
+		{req, `{"seq":7,"type":"request","command":"setBreakpoints","arguments":{"source":{"path":"a.go"}}}`},
+		{resp, `{"seq":0,"type":"response","request_seq":7,"success":true,"command":"setBreakpoints","body":{"breakpoints":[{"verified":true}]}}`},
+		{event, `{"seq":0,"type":"event","event":"stopped","body":{"reason":"step"}}`},
+		{NewThreadsRequest(), `{"seq":0,"type":"request","command":"threads"}`},
+		{NewInitializedEvent(), `{"seq":0,"type":"event","event":"initialized"}`},
+	} {
+		data, err := json.Marshal(test.m)
+		if err != nil || string(data) != test.want {
+			t.Errorf("got %s, err=%v, want %s", data, err, test.want)
+		}
+		if _, err := DecodeProtocolMessage(data); err != nil {
+			t.Errorf("DecodeProtocolMessage(%s) failed with %v", data, err)
+		}
+	}
+	if resp.Command != CommandSetBreakpoints || event.Event.Event != EventStopped {
+		t.Errorf("got command %q, event %q", resp.Command, event.Event.Event)
+	}
+}
@@ -0,0 +1,587 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains a Session, which handles one end of a DAP connection by
+// dispatching the requests it receives to a Handler and correlating the
+// requests it sends with their responses, and functions to serve sessions
+// over a listener or stdio.
+
+package dap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+)
+
+// ErrSessionClosed is returned by Session.Call and Client.Call when the
+// connection is closed before a response is received.
+var ErrSessionClosed = errors.New("dap: session closed")
+
+// ResponseError is returned by Session.Call and Client.Call when the other
+// side replies to a request with an ErrorResponse. It wraps the *Error the
+// response describes.
+type ResponseError struct {
+	Response *ErrorResponse
+}
+
+func (e *ResponseError) Error() string {
+	msg := e.Response.Message
+	if msg == "" && e.Response.Body.Error != nil {
+		msg = e.Response.Body.Error.Format
+	}
+	return fmt.Sprintf("%s request failed: %s", e.Response.Command, msg)
+}
+
+func (e *ResponseError) Unwrap() error {
+	return ErrorFromResponse(e.Response)
+}
+
+// Session is one end of a DAP connection. It reads messages from the
+// connection, dispatches each request to a Handler in its own goroutine and
+// writes back the responses, assigning the Seq of every outgoing message.
+//
+// A session is bidirectional: besides serving the requests it receives, it
+// can send requests of its own with Call and wait for the matching
+// responses. A debug adapter uses this for reverse requests such as
+// runInTerminal and startDebugging, while a Client uses it to talk to the
+// adapter. Writes are serialized, so Send and Call are safe for concurrent use.
+type Session struct {
+	rwc io.ReadWriteCloser
+	r   *Reader
+	w   *SeqWriter
+
+	// onEvent, if not nil, is called by Serve with every event received.
+	onEvent func(EventMessage)
+
+	// mu guards the fields below.
+	mu         sync.Mutex
+	pending    map[int]chan callResult
+	inflight   map[int]*requestState
+	progress   map[string]*Progress
+	progressID int
+	initArgs   *InitializeRequestArguments
+	tracker    *StateTracker
+	err        error
+	closed     bool
+	// failure is the panic of a function arranged with AfterResponse,
+	// which ended the session.
+	failure error
+
+	// wg tracks the goroutines handling requests.
+	wg sync.WaitGroup
+}
+
+type callResult struct {
+	resp ResponseMessage
+	err  error
+}
+
+// NewSession returns a session that communicates over rwc. Messages are not
+// read until Serve is called.
+func NewSession(rwc io.ReadWriteCloser) *Session {
+	return &Session{
+		rwc:      rwc,
+		r:        NewReader(rwc),
+		w:        NewSeqWriter(rwc),
+		pending:  make(map[int]chan callResult),
+		inflight: make(map[int]*requestState),
+		progress: make(map[string]*Progress),
+	}
+}
+
+// Serve reads messages from the connection until it is closed or a read
+// fails. Requests are dispatched to h, and responses are delivered to the
+// pending calls they answer. It waits for all in-flight handlers to return
+// before returning itself. The context passed to the handlers is cancelled
+// once reading stops.
+//
+// A handler that returns an error or panics results in an ErrorResponse,
+// which carries the ErrorMessage of the error if it is or wraps an *Error.
+// The context of a handler is also cancelled when a cancel request for its
+// request arrives. The response to a cancelled request is replaced with an
+// ErrorResponse with the message "cancelled", as the specification requires,
+// whatever the handler returns. Cancel requests are still dispatched to h,
+// after the cancellation has been carried out.
+//
+// Requests that cannot be decoded are answered with an ErrorResponse, as
+// are requests for unknown commands. Serve returns nil if the other side
+// closed the connection or Close was called, and the read error otherwise.
+// A function arranged with AfterResponse that panics ends the session, as
+// its response has already been sent, and Serve returns the panic as an
+// error.
+func (s *Session) Serve(h Handler) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		s.wg.Wait()
+	}()
+
+	for {
+		content, err := s.r.ReadBaseMessage()
+		if err != nil {
+			if failure := s.failed(); failure != nil {
+				err = failure
+			} else if err == io.EOF || s.isClosed() {
+				err = nil
+			}
+			s.shutdown(err)
+			return err
+		}
+		msg, err := DecodeProtocolMessage(content)
+		if err != nil {
+			s.rejectMessage(msg, content, err)
+			continue
+		}
+		s.observe(msg, false)
+		switch m := msg.(type) {
+		case RequestMessage:
+			// Register the request and carry out cancellations before reading
+			// on, so that a cancel request always finds the requests before it.
+			rs := s.startRequest(ctx, m)
+			switch m := m.(type) {
+			case *CancelRequest:
+				s.cancel(content)
+			case *InitializeRequest:
+				s.mu.Lock()
+				s.initArgs = &m.Arguments
+				s.mu.Unlock()
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.handleRequest(rs, h, m)
+			}()
+		case ResponseMessage:
+			if ch := s.removePending(m.GetResponse().RequestSeq); ch != nil {
+				ch <- callResult{resp: m}
+			}
+		case EventMessage:
+			if s.onEvent != nil {
+				s.onEvent(m)
+			}
+		}
+	}
+}
+
+// Send writes m to the connection. The Seq of m is assigned by the session,
+// and its Type, as well as its Command or Event if empty, are filled in.
+func (s *Session) Send(m Message) error {
+	fillHeader(m)
+	return s.w.writeMessage(m, func(int) error {
+		return s.observe(m, true)
+	})
+}
+
+// Call sends req to the other side and blocks until the matching response
+// arrives. See CallContext for details.
+func (s *Session) Call(req RequestMessage) (ResponseMessage, error) {
+	return s.CallContext(context.Background(), req)
+}
+
+// CallContext sends req to the other side and blocks until the matching
+// response arrives or ctx is done. The Seq and Type fields of req are filled
+// in by the session, and so is Command if it is empty. Responses are only
+// received while Serve is running.
+//
+// If the other side replies with an ErrorResponse, it is returned together
+// with a *ResponseError wrapping it.
+func (s *Session) CallContext(ctx context.Context, req RequestMessage) (ResponseMessage, error) {
+	fillHeader(req)
+	seq := 0
+	ch := make(chan callResult, 1)
+	// Register the call before the request is written, so that a quick
+	// response cannot arrive before it is expected.
+	var registerErr error
+	err := s.w.writeMessage(req, func(n int) error {
+		if registerErr = s.observe(req, true); registerErr != nil {
+			return registerErr
+		}
+		seq = n
+		registerErr = s.addPending(seq, ch)
+		return registerErr
+	})
+	if err != nil {
+		if seq != 0 {
+			s.removePending(seq)
+		}
+		// A write that fails because the session was closed meanwhile
+		// reports it as any other call on a closed session does, rather
+		// than with the error of the connection.
+		if err != registerErr && s.closedOrEnded() {
+			err = ErrSessionClosed
+		}
+		return nil, err
+	}
+
+	select {
+	case res := <-ch:
+		if res.err != nil {
+			return res.resp, res.err
+		}
+		if er, ok := res.resp.(*ErrorResponse); ok {
+			return er, &ResponseError{Response: er}
+		}
+		return res.resp, nil
+	case <-ctx.Done():
+		s.removePending(seq)
+		return nil, ctx.Err()
+	}
+}
+
+// AfterResponse arranges for f to be called once the response to the
+// request handled with ctx has been sent. Handlers use it to send events
+// that must follow their response, such as the initialized event after the
+// response to initialize. If ctx does not belong to a request handled by a
+// session, f is called right away.
+func (s *Session) AfterResponse(ctx context.Context, f func()) {
+	rs, ok := ctx.Value(requestStateKey{}).(*requestState)
+	if !ok {
+		f()
+		return
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.after = append(rs.after, f)
+}
+
+// InitializeArguments returns the arguments of the initialize request
+// received from the client, which describe its capabilities, or nil if
+// there was none yet.
+func (s *Session) InitializeArguments() *InitializeRequestArguments {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.initArgs
+}
+
+// SetReadLimits sets the maximum header and content lengths of the messages
+// the session reads, which are DefaultMaxHeaderLength and
+// DefaultMaxContentLength by default. A zero value selects the default and
+// a negative value disables the limit; see Reader. It must be called before
+// Serve.
+func (s *Session) SetReadLimits(maxHeaderLength int, maxContentLength int64) {
+	s.r.MaxHeaderLength = maxHeaderLength
+	s.r.MaxContentLength = maxContentLength
+}
+
+// SetFramingErrorHandler makes the session recover from framing errors in
+// the messages it reads, such as a malformed header, instead of returning
+// from Serve. Each error is reported to f with the number of bytes skipped
+// to find the next message; see Reader.OnFramingError. It must be called
+// before Serve.
+func (s *Session) SetFramingErrorHandler(f func(err error, discarded int64)) {
+	s.r.OnFramingError = f
+}
+
+// SetStateTracker makes the session feed every message it sends and
+// receives to t, which reports the messages that are out of order with
+// respect to the lifecycle of the debug session. If t.Strict is true, Send
+// and Call refuse to send such messages. It should be called before Serve.
+func (s *Session) SetStateTracker(t *StateTracker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tracker = t
+}
+
+// observe feeds m, which is being sent if sending is true and was received
+// otherwise, to the state tracker of the session, if any. It returns the
+// error that prevents m from being sent in strict mode.
+func (s *Session) observe(m Message, sending bool) error {
+	s.mu.Lock()
+	t := s.tracker
+	s.mu.Unlock()
+	if t == nil {
+		return nil
+	}
+	_, isResponse := m.(ResponseMessage)
+	strict := sending && t.Strict && !isResponse
+	err := t.observe(m, strict)
+	if err != nil && t.OnError != nil {
+		t.OnError(err)
+	}
+	if strict {
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection, which makes Serve return. Calls waiting for
+// a response return ErrSessionClosed.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	return s.rwc.Close()
+}
+
+func (s *Session) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// closedOrEnded reports whether the session was closed, by Close or by
+// the other side.
+func (s *Session) closedOrEnded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed || s.err == ErrSessionClosed
+}
+
+func (s *Session) addPending(seq int, ch chan callResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.pending[seq] = ch
+	return nil
+}
+
+func (s *Session) removePending(seq int) chan callResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch := s.pending[seq]
+	delete(s.pending, seq)
+	return ch
+}
+
+// shutdown records the error that ended Serve, or ErrSessionClosed if err
+// is nil, and unblocks all calls waiting for a response.
+func (s *Session) shutdown(err error) {
+	if err == nil {
+		err = ErrSessionClosed
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	for seq, ch := range s.pending {
+		ch <- callResult{err: err}
+		delete(s.pending, seq)
+	}
+}
+
+// requestStateKey is the context key for the *requestState of the request
+// being handled.
+type requestStateKey struct{}
+
+// requestState holds what a session tracks about a request while its
+// handler runs.
+type requestState struct {
+	seq    int
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	after     []func()
+	cancelled bool
+}
+
+// startRequest registers req as in flight and returns its state, with a
+// context derived from ctx.
+func (s *Session) startRequest(ctx context.Context, req RequestMessage) *requestState {
+	rs := &requestState{seq: req.GetSeq()}
+	rs.ctx, rs.cancel = context.WithCancel(context.WithValue(ctx, requestStateKey{}, rs))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inflight[req.GetSeq()] = rs
+	return rs
+}
+
+// cancel carries out the cancel request with the given content by
+// cancelling the context of the in-flight request and of the cancellable
+// progress it identifies, if any. The arguments are decoded again, as a
+// missing requestId cannot be told apart from 0, the seq of a request that
+// can be cancelled, once decoded into CancelArguments.
+func (s *Session) cancel(content []byte) {
+	var req struct {
+		Arguments struct {
+			RequestId  *int   `json:"requestId"`
+			ProgressId string `json:"progressId"`
+		} `json:"arguments"`
+	}
+	if err := json.Unmarshal(content, &req); err != nil {
+		return
+	}
+	args := req.Arguments
+	var rs *requestState
+	s.mu.Lock()
+	if args.RequestId != nil {
+		rs = s.inflight[*args.RequestId]
+	}
+	p := s.progress[args.ProgressId]
+	s.mu.Unlock()
+	if rs != nil {
+		rs.mu.Lock()
+		rs.cancelled = true
+		rs.mu.Unlock()
+		rs.cancel()
+	}
+	if p != nil && p.cancellable {
+		p.cancel()
+	}
+}
+
+func (s *Session) handleRequest(rs *requestState, h Handler, req RequestMessage) {
+	resp, err := dispatchRecover(rs.ctx, h, req)
+
+	s.mu.Lock()
+	delete(s.inflight, req.GetSeq())
+	s.mu.Unlock()
+	rs.cancel()
+
+	rs.mu.Lock()
+	cancelled := rs.cancelled
+	after := rs.after
+	rs.mu.Unlock()
+	if cancelled {
+		resp, err = nil, errCancelled
+	}
+	s.respond(req, resp, err)
+	for _, f := range after {
+		if !s.runAfter(f) {
+			return
+		}
+	}
+}
+
+// runAfter calls f, which was arranged with AfterResponse, and reports
+// whether it returned. If f panics, the session ends.
+func (s *Session) runAfter(f func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.mu.Lock()
+			if s.failure == nil {
+				s.failure = fmt.Errorf("panic after response: %v", r)
+			}
+			s.mu.Unlock()
+			s.rwc.Close()
+			ok = false
+		}
+	}()
+	f()
+	return true
+}
+
+// failed returns the panic that ended the session, if any.
+func (s *Session) failed() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failure
+}
+
+// dispatchRecover dispatches req to h, turning a panic into an error.
+func dispatchRecover(ctx context.Context, h Handler, req RequestMessage) (resp ResponseMessage, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			resp, err = nil, fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return dispatchRequest(ctx, h, req)
+}
+
+// errCancelled is the error of requests cancelled by a cancel request.
+// Its text is the message the specification requires in the ErrorResponse.
+var errCancelled = errors.New(ResponseMessageCancelled)
+
+// respond sends resp as the response to req, or an ErrorResponse if err is
+// not nil.
+func (s *Session) respond(req RequestMessage, resp ResponseMessage, err error) {
+	if err != nil {
+		var details *ErrorMessage
+		var de *Error
+		if errors.As(err, &de) {
+			details = de.ErrorMessage()
+		}
+		s.Send(NewErrorResponse(req, err.Error(), details))
+		return
+	}
+	r := req.GetRequest()
+	rr := resp.GetResponse()
+	rr.RequestSeq = r.Seq
+	rr.Command = r.Command
+	rr.Success = true
+	s.Send(resp)
+}
+
+// rejectMessage handles a message whose content failed to decode with err.
+// A request is answered with an ErrorResponse, and a call waiting for a
+// response gets err. Other messages are dropped.
+func (s *Session) rejectMessage(msg Message, content []byte, err error) {
+	var fe *DecodeProtocolMessageFieldError
+	if errors.As(err, &fe) && fe.SubType == "Request" {
+		req := &Request{Command: fe.FieldValue}
+		req.Seq = fe.Seq
+		s.respond(req, nil, err)
+		return
+	}
+	if req, ok := msg.(RequestMessage); ok {
+		s.respond(req, nil, err)
+		return
+	}
+	var rm struct {
+		Type       string `json:"type"`
+		RequestSeq int    `json:"request_seq"`
+	}
+	if json.Unmarshal(content, &rm) == nil && rm.Type == "response" {
+		if ch := s.removePending(rm.RequestSeq); ch != nil {
+			ch <- callResult{err: err}
+		}
+	}
+}
+
+// Serve accepts connections on l and serves a new Session on each one in
+// its own goroutine, with the Handler returned by newHandler for that
+// session. The connection is closed when the session ends. Serve returns
+// the error from l.Accept when it fails.
+func Serve(l net.Listener, newHandler func(*Session) Handler) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			s := NewSession(conn)
+			s.Serve(newHandler(s))
+		}()
+	}
+}
+
+// ServeStdio serves a single Session over the standard input and output of
+// the process, which is how IDEs usually talk to debug adapters they launch.
+// Nothing else may write to standard output while the session is running.
+func ServeStdio(newHandler func(*Session) Handler) error {
+	s := NewSession(stdio{})
+	return s.Serve(newHandler(s))
+}
+
+// stdio is an io.ReadWriteCloser over the standard input and output.
+type stdio struct{}
+
+func (stdio) Read(p []byte) (int, error)  { return os.Stdin.Read(p) }
+func (stdio) Write(p []byte) (int, error) { return os.Stdout.Write(p) }
+
+func (stdio) Close() error {
+	err := os.Stdin.Close()
+	if err2 := os.Stdout.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
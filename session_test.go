@@ -0,0 +1,414 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dap
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testHandler supports initialize and threads requests only.
+type testHandler struct {
+	UnimplementedHandler
+	s *Session
+}
+
+func (h *testHandler) OnInitializeRequest(ctx context.Context, req *InitializeRequest) (*InitializeResponse, error) {
+	h.s.AfterResponse(ctx, func() {
+		h.s.Send(&InitializedEvent{})
+	})
+	return &InitializeResponse{Body: Capabilities{SupportsConfigurationDoneRequest: true}}, nil
+}
+
+func (h *testHandler) OnThreadsRequest(ctx context.Context, req *ThreadsRequest) (*ThreadsResponse, error) {
+	return &ThreadsResponse{Body: ThreadsResponseBody{Threads: []Thread{{Id: 1, Name: "main"}}}}, nil
+}
+
+// startSession serves a session with the handler returned by newHandler
+// over one end of a pipe and returns a client connected to the other end.
+func startSession(t *testing.T, newHandler func(*Session) Handler) *Client {
+	clientConn, adapterConn := net.Pipe()
+	s := NewSession(adapterConn)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := s.Serve(newHandler(s)); err != nil {
+			t.Error(err)
+		}
+	}()
+	c := NewClient(clientConn)
+	t.Cleanup(func() {
+		c.Close()
+		<-done
+	})
+	return c
+}
+
+func TestSession(t *testing.T) {
+	c := startSession(t, func(s *Session) Handler { return &testHandler{s: s} })
+
+	resp, err := c.Call(&InitializeRequest{Arguments: InitializeRequestArguments{AdapterID: "go"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ir, ok := resp.(*InitializeResponse)
+	if !ok {
+		t.Fatalf("got %#v, want *InitializeResponse", resp)
+	}
+	wantResp := Response{
+		ProtocolMessage: ProtocolMessage{Seq: 1, Type: "response"},
+		RequestSeq:      1,
+		Command:         "initialize",
+		Success:         true,
+	}
+	if ir.Response != wantResp || !ir.Body.SupportsConfigurationDoneRequest {
+		t.Errorf("got %#v, want %#v with body", ir, wantResp)
+	}
+	e := <-c.Events()
+	ie, ok := e.(*InitializedEvent)
+	if !ok || ie.Seq != 2 || ie.Event.Event != "initialized" {
+		t.Errorf("got %#v, want initialized event with seq 2", e)
+	}
+
+	resp, err = c.Call(&ThreadsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr, ok := resp.(*ThreadsResponse); !ok || tr.Seq != 3 || tr.RequestSeq != 2 || len(tr.Body.Threads) != 1 {
+		t.Errorf("got %#v, want threads response with seq 3", resp)
+	}
+
+	_, err = c.Call(&PauseRequest{})
+	var re *ResponseError
+	if !errors.As(err, &re) || re.Response.Message != "pause request is not supported" {
+		t.Errorf("got err=%v, want pause request is not supported", err)
+	}
+}
+
+func TestSessionDispatchesEveryRequest(t *testing.T) {
+	c := startSession(t, func(*Session) Handler { return UnimplementedHandler{} })
+	for command, ctor := range requestCtor {
+		_, err := c.Call(ctor().(RequestMessage))
+		if command == "cancel" {
+			if err != nil {
+				t.Errorf("%s: got err=%v, want success", command, err)
+			}
+			continue
+		}
+		var re *ResponseError
+		if !errors.As(err, &re) || re.Response.Command != command || re.Response.Message != command+" request is not supported" {
+			t.Errorf("%s: got err=%v, want unsupported", command, err)
+		}
+	}
+}
+
+// panicHandler panics while handling threads requests, and after
+// responding to initialize requests.
+type panicHandler struct {
+	UnimplementedHandler
+	s      *Session
+	called bool
+}
+
+func (h *panicHandler) OnInitializeRequest(ctx context.Context, req *InitializeRequest) (*InitializeResponse, error) {
+	h.s.AfterResponse(ctx, func() { panic("boom") })
+	h.s.AfterResponse(ctx, func() { h.called = true })
+	return &InitializeResponse{}, nil
+}
+
+func (h *panicHandler) OnThreadsRequest(ctx context.Context, req *ThreadsRequest) (*ThreadsResponse, error) {
+	panic("boom")
+}
+
+func TestSessionHandlerPanic(t *testing.T) {
+	c := startSession(t, func(s *Session) Handler { return &panicHandler{s: s} })
+
+	_, err := c.Call(&ThreadsRequest{})
+	var re *ResponseError
+	if !errors.As(err, &re) || re.Response.Command != "threads" || re.Response.Message != "panic: boom" {
+		t.Errorf("got err=%v, want panic: boom", err)
+	}
+	// The session goes on serving requests.
+	_, err = c.Call(&PauseRequest{})
+	if !errors.As(err, &re) || re.Response.Message != "pause request is not supported" {
+		t.Errorf("got err=%v, want pause request is not supported", err)
+	}
+}
+
+func TestSessionAfterResponsePanic(t *testing.T) {
+	clientConn, adapterConn := net.Pipe()
+	s := NewSession(adapterConn)
+	h := &panicHandler{s: s}
+	done := make(chan error)
+	go func() { done <- s.Serve(h) }()
+	c := NewClient(clientConn)
+	defer c.Close()
+
+	if _, err := c.Call(&InitializeRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	// The panic ends the session, rather than the process.
+	if err := <-done; err == nil || err.Error() != "panic after response: boom" {
+		t.Errorf("got err=%v, want panic after response: boom", err)
+	}
+	if h.called {
+		t.Error("the function arranged after the panicking one was called")
+	}
+}
+
+func TestHandlerFunc(t *testing.T) {
+	frames := []StackFrame{{Id: 1, Name: "main"}}
+	c := startSession(t, func(*Session) Handler {
+		return HandlerFunc(func(ctx context.Context, req RequestMessage) (ResponseMessage, error) {
+			switch req.(type) {
+			case *StackTraceRequest:
+				return &StackTraceResponse{Body: StackTraceResponseBody{StackFrames: frames}}, nil
+			case *ThreadsRequest:
+				return &StackTraceResponse{}, nil
+			}
+			return nil, nil
+		})
+	})
+
+	resp, err := c.Call(&StackTraceRequest{})
+	if st, ok := resp.(*StackTraceResponse); err != nil || !ok || !reflect.DeepEqual(st.Body.StackFrames, frames) || !st.Success {
+		t.Errorf("got %#v, err=%v, want stack trace", resp, err)
+	}
+	if resp, err := c.Call(&PauseRequest{}); err != nil || resp.GetResponse().Command != "pause" {
+		t.Errorf("got %#v, err=%v, want empty pause response", resp, err)
+	}
+	var re *ResponseError
+	if _, err := c.Call(&ThreadsRequest{}); !errors.As(err, &re) || re.Response.Message != "*dap.StackTraceResponse is not a response to a threads request" {
+		t.Errorf("got err=%v, want mismatched response error", err)
+	}
+}
+
+func TestSessionRejectsUnknownRequest(t *testing.T) {
+	clientConn, adapterConn := net.Pipe()
+	s := NewSession(adapterConn)
+	go s.Serve(UnimplementedHandler{})
+	defer s.Close()
+
+	go WriteBaseMessage(clientConn, []byte(`{"seq":7,"type":"request","command":"foo"}`))
+	msg, err := ReadProtocolMessage(bufio.NewReader(clientConn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	er, ok := msg.(*ErrorResponse)
+	if !ok || er.RequestSeq != 7 || er.Command != "foo" || er.Success {
+		t.Errorf("got %#v, want error response to foo", msg)
+	}
+}
+
+func TestSessionRecoversFromFramingErrors(t *testing.T) {
+	clientConn, adapterConn := net.Pipe()
+	s := NewSession(adapterConn)
+	discarded := make(chan int64, 1)
+	s.SetFramingErrorHandler(func(err error, n int64) { discarded <- n })
+	go s.Serve(UnimplementedHandler{})
+	defer s.Close()
+
+	go func() {
+		clientConn.Write([]byte("Content-Length: x\r\n\r\ngarbage"))
+		WriteBaseMessage(clientConn, []byte(`{"seq":7,"type":"request","command":"threads"}`))
+	}()
+	msg, err := ReadProtocolMessage(bufio.NewReader(clientConn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if er, ok := msg.(*ErrorResponse); !ok || er.RequestSeq != 7 {
+		t.Errorf("got %#v, want error response to request 7", msg)
+	}
+	if n := <-discarded; n != int64(len("garbage")) {
+		t.Errorf("got %d bytes discarded, want %d", n, len("garbage"))
+	}
+}
+
+// terminalLauncher launches the debuggee in the client's terminal.
+type terminalLauncher struct {
+	UnimplementedHandler
+	s *Session
+}
+
+func (h *terminalLauncher) OnLaunchRequest(ctx context.Context, req *LaunchRequest) (*LaunchResponse, error) {
+	resp, err := h.s.CallContext(ctx, &RunInTerminalRequest{
+		Arguments: RunInTerminalRequestArguments{Kind: "integrated", Args: []string{"hello"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if pid := resp.(*RunInTerminalResponse).Body.ProcessId; pid != 42 {
+		return nil, fmt.Errorf("got pid %d, want 42", pid)
+	}
+	return nil, nil
+}
+
+// terminal serves runInTerminal requests on the client side.
+type terminal struct {
+	UnimplementedHandler
+}
+
+func (terminal) OnRunInTerminalRequest(ctx context.Context, req *RunInTerminalRequest) (*RunInTerminalResponse, error) {
+	if len(req.Arguments.Args) != 1 || req.Arguments.Args[0] != "hello" {
+		return nil, fmt.Errorf("got args %q, want [hello]", req.Arguments.Args)
+	}
+	return &RunInTerminalResponse{Body: RunInTerminalResponseBody{ProcessId: 42}}, nil
+}
+
+func TestSessionCallWhileClosing(t *testing.T) {
+	conn, other := net.Pipe()
+	defer other.Close()
+	s := NewSession(conn)
+
+	// Nothing reads the other end, so the request is still being written
+	// when the session closes.
+	done := make(chan error)
+	go func() {
+		_, err := s.Call(&ThreadsRequest{})
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	s.Close()
+	if err := <-done; !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("got err=%v, want %v", err, ErrSessionClosed)
+	}
+	if _, err := s.Call(&ThreadsRequest{}); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("got err=%v after Close, want %v", err, ErrSessionClosed)
+	}
+}
+
+func TestSessionReverseRequest(t *testing.T) {
+	clientConn, adapterConn := net.Pipe()
+	s := NewSession(adapterConn)
+	go s.Serve(&terminalLauncher{s: s})
+	defer s.Close()
+
+	c := NewClient(clientConn, ReverseRequestHandler(terminal{}))
+	defer c.Close()
+	if _, err := c.Call(&LaunchRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a handler, the client rejects the reverse request, which
+	// fails the launch.
+	c2Conn, adapter2Conn := net.Pipe()
+	s2 := NewSession(adapter2Conn)
+	go s2.Serve(&terminalLauncher{s: s2})
+	defer s2.Close()
+
+	c2 := NewClient(c2Conn)
+	defer c2.Close()
+	_, err := c2.Call(&LaunchRequest{})
+	var re *ResponseError
+	if !errors.As(err, &re) || re.Response.Message != "runInTerminal request failed: runInTerminal request is not supported" {
+		t.Errorf("got err=%v, want runInTerminal failure", err)
+	}
+}
+
+// slowStackTracer handles stackTrace requests until they are cancelled.
+type slowStackTracer struct {
+	UnimplementedHandler
+	started chan int
+}
+
+func (h *slowStackTracer) OnStackTraceRequest(ctx context.Context, req *StackTraceRequest) (*StackTraceResponse, error) {
+	h.started <- req.Seq
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSessionCancelRequest(t *testing.T) {
+	h := &slowStackTracer{started: make(chan int)}
+	c := startSession(t, func(*Session) Handler { return h })
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Call(&StackTraceRequest{Arguments: StackTraceArguments{ThreadId: 1}})
+		done <- err
+	}()
+	seq := <-h.started
+	if _, err := c.Call(&CancelRequest{Arguments: &CancelArguments{RequestId: seq}}); err != nil {
+		t.Fatal(err)
+	}
+	err := <-done
+	var re *ResponseError
+	if !errors.As(err, &re) || re.Response.Message != "cancelled" || re.Response.RequestSeq != seq {
+		t.Errorf("got err=%v, want cancelled response to request %d", err, seq)
+	}
+
+	// Cancelling a request that is not in flight is a no-op.
+	if _, err := c.Call(&CancelRequest{Arguments: &CancelArguments{RequestId: seq}}); err != nil {
+		t.Error(err)
+	}
+}
+
+// blockingStackTracer hands over the context of stackTrace requests and
+// handles them until they are cancelled.
+type blockingStackTracer struct {
+	UnimplementedHandler
+	started chan context.Context
+}
+
+func (h *blockingStackTracer) OnStackTraceRequest(ctx context.Context, req *StackTraceRequest) (*StackTraceResponse, error) {
+	h.started <- ctx
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSessionCancelRequestSeqZero(t *testing.T) {
+	clientConn, adapterConn := net.Pipe()
+	s := NewSession(adapterConn)
+	h := &blockingStackTracer{started: make(chan context.Context)}
+	go s.Serve(h)
+	defer s.Close()
+	r := bufio.NewReader(clientConn)
+
+	go WriteBaseMessage(clientConn, []byte(`{"seq":0,"type":"request","command":"stackTrace","arguments":{"threadId":1}}`))
+	ctx := <-h.started
+
+	// A cancel request without a requestId does not cancel request 0.
+	go WriteBaseMessage(clientConn, []byte(`{"seq":1,"type":"request","command":"cancel","arguments":{}}`))
+	msg, err := ReadProtocolMessage(r)
+	if resp, ok := msg.(*CancelResponse); err != nil || !ok || resp.RequestSeq != 1 {
+		t.Fatalf("got %#v, err=%v, want cancel response", msg, err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("request 0 was cancelled by a cancel request without a requestId")
+	}
+
+	// One with requestId 0 does.
+	go WriteBaseMessage(clientConn, []byte(`{"seq":2,"type":"request","command":"cancel","arguments":{"requestId":0}}`))
+	for i := 0; i < 2; i++ {
+		msg, err := ReadProtocolMessage(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch resp := msg.(type) {
+		case *CancelResponse:
+		case *ErrorResponse:
+			if resp.RequestSeq != 0 || resp.Message != "cancelled" {
+				t.Errorf("got %#v, want cancelled response to request 0", resp)
+			}
+		default:
+			t.Errorf("got %#v, want cancel or cancelled response", msg)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains a tracker of the lifecycle of a debug session, which
+// detects messages exchanged in an order the specification does not allow.
+// For additional information, see "Initialization" and "Debug session end"
+// in https://microsoft.github.io/debug-adapter-protocol/overview.
+
+package dap
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SessionState is a stage in the lifecycle of a debug session.
+type SessionState int
+
+const (
+	// StateUninitialized is the state before the initialize request.
+	StateUninitialized SessionState = iota
+	// StateInitializing is the state after the initialize request, while
+	// its response is pending.
+	StateInitializing
+	// StateInitialized is the state after a successful initialize response,
+	// before the debug adapter sends the initialized event.
+	StateInitialized
+	// StateConfiguring is the state after the initialized event, in which
+	// the client sends configuration requests such as setBreakpoints.
+	StateConfiguring
+	// StateConfigured is the state after the response to configurationDone.
+	StateConfigured
+	// StateTerminated is the state after the response to terminate or the
+	// terminated event, in which the client may only request restart,
+	// terminate or disconnect. A successful restart returns the session to
+	// the state it was in before.
+	StateTerminated
+	// StateDisconnected is the state after the response to disconnect, in
+	// which no more messages may be exchanged.
+	StateDisconnected
+)
+
+var sessionStateNames = [...]string{
+	StateUninitialized: "uninitialized",
+	StateInitializing:  "initializing",
+	StateInitialized:   "initialized",
+	StateConfiguring:   "configuring",
+	StateConfigured:    "configured",
+	StateTerminated:    "terminated",
+	StateDisconnected:  "disconnected",
+}
+
+func (s SessionState) String() string {
+	if s < 0 || int(s) >= len(sessionStateNames) {
+		return fmt.Sprintf("SessionState(%d)", int(s))
+	}
+	return sessionStateNames[s]
+}
+
+// ProtocolOrderError describes a message that is out of order with respect
+// to the lifecycle of the debug session.
+type ProtocolOrderError struct {
+	// State is the state of the session when the message was observed.
+	State   SessionState
+	Message Message
+	// Reason explains which rule of the specification the message breaks.
+	Reason string
+}
+
+func (e *ProtocolOrderError) Error() string {
+	return fmt.Sprintf("%s (seq: %d) out of order in %s state: %s", describeMessage(e.Message), e.Message.GetSeq(), e.State, e.Reason)
+}
+
+// describeMessage returns a short description of m, such as
+// "launch request" or "stopped event".
+func describeMessage(m Message) string {
+	switch m := m.(type) {
+	case RequestMessage:
+		return m.GetRequest().Command + " request"
+	case ResponseMessage:
+		return m.GetResponse().Command + " response"
+	case EventMessage:
+		return m.GetEvent().Event + " event"
+	}
+	return fmt.Sprintf("%T", m)
+}
+
+// StateTracker follows the lifecycle of a debug session as every message
+// exchanged, in either direction, is fed to Observe, and reports messages
+// that are out of order as *ProtocolOrderError. The order enforced is:
+// the initialize request and its response, before anything else; the
+// initialized event; configuration requests ending in configurationDone;
+// launch or attach at most once; only restart, terminate or disconnect
+// once the debuggee has terminated; and nothing after the disconnect
+// response.
+// It can be used by clients and debug adapters alike, and is safe for
+// concurrent use.
+//
+// A Session uses a tracker set with Session.SetStateTracker to observe the
+// messages it sends and receives.
+type StateTracker struct {
+	// Strict makes a Session refuse to send requests and events that are out
+	// of order: Session.Send and Session.Call return the *ProtocolOrderError
+	// instead. Responses are always sent, so that no request is left
+	// unanswered.
+	Strict bool
+	// OnError, if not nil, is called by a Session with the error for every
+	// out-of-order message it sends or receives.
+	OnError func(error)
+
+	mu       sync.Mutex
+	state    SessionState
+	launched bool
+	// resumed is the state that a successful restart returns to from
+	// StateTerminated.
+	resumed SessionState
+}
+
+// State returns the current state of the session.
+func (t *StateTracker) State() SessionState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
+
+// Check reports whether m would be in order if it was exchanged next,
+// without recording it.
+func (t *StateTracker) Check(m Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.check(m)
+}
+
+// Observe records that m was exchanged and reports whether it was in order.
+// Out-of-order messages are recorded all the same.
+func (t *StateTracker) Observe(m Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	err := t.check(m)
+	t.advance(m)
+	return err
+}
+
+// observe is like Observe, but if strict is true, an out-of-order message
+// is not recorded.
+func (t *StateTracker) observe(m Message, strict bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	err := t.check(m)
+	if err == nil || !strict {
+		t.advance(m)
+	}
+	return err
+}
+
+// isConfigurationRequest reports whether command belongs to the requests
+// the client sends between the initialized event and configurationDone.
+func isConfigurationRequest(command string) bool {
+	switch command {
+	case "setBreakpoints", "setFunctionBreakpoints", "setExceptionBreakpoints",
+		"setDataBreakpoints", "setInstructionBreakpoints", "configurationDone":
+		return true
+	}
+	return false
+}
+
+// isReverseRequest reports whether command is a request sent by the debug
+// adapter to the client.
+func isReverseRequest(command string) bool {
+	return command == "runInTerminal" || command == "startDebugging"
+}
+
+func (t *StateTracker) check(m Message) error {
+	reason := t.violation(m)
+	if reason == "" {
+		return nil
+	}
+	return &ProtocolOrderError{State: t.state, Message: m, Reason: reason}
+}
+
+// violation returns why m is out of order in the current state, or an
+// empty string if it is not.
+func (t *StateTracker) violation(m Message) string {
+	if t.state == StateDisconnected {
+		return "no messages may follow the disconnect response"
+	}
+	switch m := m.(type) {
+	case RequestMessage:
+		command := m.GetRequest().Command
+		switch {
+		case t.state == StateTerminated && command != "restart" && command != "terminate" && command != "disconnect":
+			return "only restart, terminate or disconnect may be requested once the debuggee has terminated"
+		case isReverseRequest(command):
+			if t.state < StateInitialized {
+				return "the debug adapter must not send requests before the initialize response"
+			}
+		case command == "initialize":
+			if t.state != StateUninitialized {
+				return "initialize must be requested only once"
+			}
+		case t.state < StateInitialized:
+			return "the client must not send requests before the initialize response"
+		case isConfigurationRequest(command) && t.state < StateConfiguring:
+			return "configuration requests must follow the initialized event"
+		case command == "configurationDone" && t.state >= StateConfigured:
+			return "configurationDone must be requested only once"
+		case (command == "launch" || command == "attach") && t.launched:
+			return "launch or attach must be requested only once"
+		}
+	case ResponseMessage:
+		command := m.GetResponse().Command
+		if command != "initialize" && !isReverseRequest(command) && t.state < StateInitialized {
+			return "the debug adapter must respond to initialize first"
+		}
+	case EventMessage:
+		if t.state < StateInitialized {
+			return "the debug adapter must not send events before the initialize response"
+		}
+		if m.GetEvent().Event == "initialized" && t.state >= StateConfiguring {
+			return "the initialized event must be sent only once"
+		}
+	}
+	return ""
+}
+
+// advance moves the session to the state that follows m.
+func (t *StateTracker) advance(m Message) {
+	switch m := m.(type) {
+	case RequestMessage:
+		switch m.GetRequest().Command {
+		case "initialize":
+			if t.state == StateUninitialized {
+				t.state = StateInitializing
+			}
+		case "launch", "attach":
+			t.launched = true
+		}
+	case ResponseMessage:
+		r := m.GetResponse()
+		switch {
+		case r.Command == "initialize" && t.state == StateInitializing:
+			if r.Success {
+				t.state = StateInitialized
+			} else {
+				t.state = StateUninitialized
+			}
+		case r.Command == "configurationDone" && r.Success && t.state < StateConfigured:
+			t.state = StateConfigured
+		case r.Command == "terminate" && r.Success && t.state >= StateInitialized && t.state < StateTerminated:
+			t.resumed, t.state = t.state, StateTerminated
+		case r.Command == "restart" && r.Success && t.state == StateTerminated:
+			t.state = t.resumed
+		case r.Command == "disconnect" && r.Success:
+			t.state = StateDisconnected
+		}
+	case EventMessage:
+		switch m.GetEvent().Event {
+		case "initialized":
+			if t.state == StateInitialized {
+				t.state = StateConfiguring
+			}
+		case "terminated":
+			if t.state >= StateInitialized && t.state < StateTerminated {
+				t.resumed, t.state = t.state, StateTerminated
+			}
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dap
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStateTracker(t *testing.T) {
+	initialize := func() []Message {
+		return []Message{
+			&InitializeRequest{Request: Request{Command: "initialize"}},
+			&InitializeResponse{Response: Response{Command: "initialize", Success: true}},
+			&InitializedEvent{Event: Event{Event: "initialized"}},
+		}
+	}
+	tests := []struct {
+		name      string
+		messages  []Message
+		wantState SessionState
+		// wantErr is the index of the first out-of-order message, or -1.
+		wantErr int
+	}{
+		{
+			name:      "full session",
+			wantState: StateDisconnected,
+			messages: append(initialize(),
+				&SetBreakpointsRequest{Request: Request{Command: "setBreakpoints"}},
+				&SetBreakpointsResponse{Response: Response{Command: "setBreakpoints", Success: true}},
+				&LaunchRequest{Request: Request{Command: "launch"}},
+				&ConfigurationDoneRequest{Request: Request{Command: "configurationDone"}},
+				&ConfigurationDoneResponse{Response: Response{Command: "configurationDone", Success: true}},
+				&LaunchResponse{Response: Response{Command: "launch", Success: true}},
+				&RunInTerminalRequest{Request: Request{Command: "runInTerminal"}},
+				&RunInTerminalResponse{Response: Response{Command: "runInTerminal", Success: true}},
+				&StoppedEvent{Event: Event{Event: "stopped"}},
+				&DisconnectRequest{Request: Request{Command: "disconnect"}},
+				&DisconnectResponse{Response: Response{Command: "disconnect", Success: true}},
+			),
+			wantErr: -1,
+		},
+		{
+			name:      "initializing",
+			wantState: StateInitializing,
+			messages:  []Message{&InitializeRequest{Request: Request{Command: "initialize"}}},
+			wantErr:   -1,
+		},
+		{
+			name:      "failed initialize",
+			wantState: StateInitializing,
+			messages: []Message{
+				&InitializeRequest{Request: Request{Command: "initialize"}},
+				&ErrorResponse{Response: Response{Command: "initialize"}},
+				&InitializeRequest{Request: Request{Command: "initialize"}},
+			},
+			wantErr: -1,
+		},
+		{
+			name:      "request before initialize",
+			wantState: StateUninitialized,
+			messages:  []Message{&ThreadsRequest{Request: Request{Command: "threads"}}},
+			wantErr:   0,
+		},
+		{
+			name:      "event before initialize response",
+			wantState: StateInitializing,
+			messages: []Message{
+				&InitializeRequest{Request: Request{Command: "initialize"}},
+				&OutputEvent{Event: Event{Event: "output"}},
+			},
+			wantErr: 1,
+		},
+		{
+			name:      "initialize twice",
+			wantState: StateConfiguring,
+			messages:  append(initialize(), &InitializeRequest{Request: Request{Command: "initialize"}}),
+			wantErr:   3,
+		},
+		{
+			name:      "configuration before initialized event",
+			wantState: StateInitialized,
+			messages: append(initialize()[:2],
+				&SetBreakpointsRequest{Request: Request{Command: "setBreakpoints"}}),
+			wantErr: 2,
+		},
+		{
+			name:      "launch twice",
+			wantState: StateConfiguring,
+			messages: append(initialize(),
+				&LaunchRequest{Request: Request{Command: "launch"}},
+				&AttachRequest{Request: Request{Command: "attach"}}),
+			wantErr: 4,
+		},
+		{
+			name:      "configurationDone twice",
+			wantState: StateConfigured,
+			messages: append(initialize(),
+				&ConfigurationDoneRequest{Request: Request{Command: "configurationDone"}},
+				&ConfigurationDoneResponse{Response: Response{Command: "configurationDone", Success: true}},
+				&ConfigurationDoneRequest{Request: Request{Command: "configurationDone"}}),
+			wantErr: 5,
+		},
+		{
+			name:      "initialized event twice",
+			wantState: StateConfiguring,
+			messages:  append(initialize(), &InitializedEvent{Event: Event{Event: "initialized"}}),
+			wantErr:   3,
+		},
+		{
+			name:      "terminate",
+			wantState: StateDisconnected,
+			messages: append(initialize(),
+				&TerminateRequest{Request: Request{Command: "terminate"}},
+				&TerminateResponse{Response: Response{Command: "terminate", Success: true}},
+				&OutputEvent{Event: Event{Event: "output"}},
+				&TerminatedEvent{Event: Event{Event: "terminated"}},
+				&DisconnectRequest{Request: Request{Command: "disconnect"}},
+				&DisconnectResponse{Response: Response{Command: "disconnect", Success: true}}),
+			wantErr: -1,
+		},
+		{
+			name:      "request after terminate",
+			wantState: StateTerminated,
+			messages: append(initialize(),
+				&TerminateRequest{Request: Request{Command: "terminate"}},
+				&TerminateResponse{Response: Response{Command: "terminate", Success: true}},
+				&ThreadsRequest{Request: Request{Command: "threads"}}),
+			wantErr: 5,
+		},
+		{
+			name:      "request after terminated event",
+			wantState: StateTerminated,
+			messages: append(initialize(),
+				&TerminatedEvent{Event: Event{Event: "terminated"}},
+				&ContinueRequest{Request: Request{Command: "continue"}}),
+			wantErr: 4,
+		},
+		{
+			name:      "restart after terminated event",
+			wantState: StateConfigured,
+			messages: append(initialize(),
+				&LaunchRequest{Request: Request{Command: "launch"}},
+				&ConfigurationDoneRequest{Request: Request{Command: "configurationDone"}},
+				&ConfigurationDoneResponse{Response: Response{Command: "configurationDone", Success: true}},
+				&LaunchResponse{Response: Response{Command: "launch", Success: true}},
+				&TerminatedEvent{Event: Event{Event: "terminated"}},
+				&TerminateRequest{Request: Request{Command: "terminate"}},
+				&TerminateResponse{Response: Response{Command: "terminate", Success: true}},
+				&RestartRequest{Request: Request{Command: "restart"}},
+				&RestartResponse{Response: Response{Command: "restart", Success: true}},
+				&StoppedEvent{Event: Event{Event: "stopped"}},
+				&ContinueRequest{Request: Request{Command: "continue"}}),
+			wantErr: -1,
+		},
+		{
+			name:      "event after disconnect",
+			wantState: StateDisconnected,
+			messages: append(initialize(),
+				&DisconnectRequest{Request: Request{Command: "disconnect"}},
+				&DisconnectResponse{Response: Response{Command: "disconnect", Success: true}},
+				&TerminatedEvent{Event: Event{Event: "terminated"}}),
+			wantErr: 5,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var tracker StateTracker
+			gotErr := -1
+			for i, m := range test.messages {
+				err := tracker.Observe(m)
+				if err == nil {
+					continue
+				}
+				var oe *ProtocolOrderError
+				if !errors.As(err, &oe) || oe.Message != m {
+					t.Fatalf("message %d: got err=%v, want *ProtocolOrderError", i, err)
+				}
+				if gotErr < 0 {
+					gotErr = i
+				}
+			}
+			if gotErr != test.wantErr {
+				t.Errorf("got first error at %d, want %d", gotErr, test.wantErr)
+			}
+			if got := tracker.State(); got != test.wantState {
+				t.Errorf("got state %s, want %s", got, test.wantState)
+			}
+		})
+	}
+}
+
+func TestSessionStrictStateTracker(t *testing.T) {
+	var s *Session
+	errs := make(chan error, 10)
+	tracker := &StateTracker{Strict: true, OnError: func(err error) { errs <- err }}
+	c := startSession(t, func(session *Session) Handler {
+		s = session
+		s.SetStateTracker(tracker)
+		return &testHandler{s: s}
+	})
+
+	// Requests received and responses sent are only reported, never refused.
+	if _, err := c.Call(&ThreadsRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"threads request", "threads response"} {
+		var oe *ProtocolOrderError
+		if err := <-errs; !errors.As(err, &oe) || describeMessage(oe.Message) != want {
+			t.Errorf("got err=%v, want %s out of order", err, want)
+		}
+	}
+
+	var oe *ProtocolOrderError
+	if err := s.Send(&OutputEvent{}); !errors.As(err, &oe) || oe.State != StateUninitialized {
+		t.Errorf("got err=%v, want *ProtocolOrderError", err)
+	}
+	if err := <-errs; err != oe {
+		t.Errorf("got err=%v reported, want %v", err, oe)
+	}
+
+	if _, err := c.Call(&InitializeRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := (<-c.Events()).(*InitializedEvent); !ok {
+		t.Error("want initialized event")
+	}
+	if err := s.Send(&OutputEvent{}); err != nil {
+		t.Error(err)
+	}
+	if _, ok := (<-c.Events()).(*OutputEvent); !ok {
+		t.Error("want output event")
+	}
+	if got := tracker.State(); got != StateConfiguring {
+		t.Errorf("got state %s, want %s", got, StateConfiguring)
+	}
+	select {
+	case err := <-errs:
+		t.Errorf("got unexpected err=%v", err)
+	default:
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains the checks of the strict decoding mode of Codec, which
+// compare the JSON encoding of a message to the Go type it is decoded into.
+
+package dap
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StrictDecodeError is returned by a Codec set with UseStrictDecoding for
+// a message that has unknown fields or lacks required properties. Fields
+// are named by their JSON path in the message, such as "arguments.threadID"
+// or "body.breakpoints[0].line".
+type StrictDecodeError struct {
+	Seq int
+	// Unknown are the paths of the fields the message type does not have.
+	Unknown []string
+	// Missing are the paths of the required properties that are absent.
+	Missing []string
+}
+
+func (e *StrictDecodeError) Error() string {
+	var problems []string
+	if len(e.Unknown) > 0 {
+		problems = append(problems, "unknown fields "+strings.Join(e.Unknown, ", "))
+	}
+	if len(e.Missing) > 0 {
+		problems = append(problems, "missing required fields "+strings.Join(e.Missing, ", "))
+	}
+	return fmt.Sprintf("strict decoding of message (seq: %d): %s", e.Seq, strings.Join(problems, "; "))
+}
+
+// checkStrict checks data, the JSON encoding of message, and returns a
+// *StrictDecodeError if it has unknown fields or lacks required properties.
+func checkStrict(seq int, data []byte, message Message) error {
+	e := &StrictDecodeError{Seq: seq}
+	e.check("", data, reflect.TypeOf(message))
+	if len(e.Unknown) == 0 && len(e.Missing) == 0 {
+		return nil
+	}
+	return e
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// check adds to e the problems of the value at path, encoded as data, that
+// is decoded into a value of type t.
+func (e *StrictDecodeError) check(path string, data json.RawMessage, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Types that decode themselves, such as generic messages, define their
+	// own encoding, which cannot be checked against their fields.
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(data, &object) != nil || object == nil {
+			return
+		}
+		fields := jsonFields(t)
+		for _, key := range fields.required {
+			if _, ok := object[key]; !ok {
+				e.Missing = append(e.Missing, joinPath(path, key))
+			}
+		}
+		for _, key := range sortedKeys(object) {
+			f, ok := fields.byName[key]
+			if !ok {
+				e.Unknown = append(e.Unknown, joinPath(path, key))
+				continue
+			}
+			e.check(joinPath(path, key), object[key], f)
+		}
+	case reflect.Slice, reflect.Array:
+		var elems []json.RawMessage
+		if json.Unmarshal(data, &elems) != nil {
+			return
+		}
+		for i, elem := range elems {
+			e.check(path+"["+strconv.Itoa(i)+"]", elem, t.Elem())
+		}
+	case reflect.Map:
+		var values map[string]json.RawMessage
+		if json.Unmarshal(data, &values) != nil {
+			return
+		}
+		for _, key := range sortedKeys(values) {
+			e.check(joinPath(path, key), values[key], t.Elem())
+		}
+	}
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// structFields describes how a struct type is encoded in JSON.
+type structFields struct {
+	// byName maps the JSON names of the fields to their types.
+	byName map[string]reflect.Type
+	// required are the JSON names of the properties the specification
+	// requires.
+	required []string
+}
+
+var structFieldsCache sync.Map // map[reflect.Type]*structFields
+
+// jsonFields returns the fields of struct type t, including the fields of
+// the structs it embeds, by their JSON names.
+func jsonFields(t reflect.Type) *structFields {
+	if f, ok := structFieldsCache.Load(t); ok {
+		return f.(*structFields)
+	}
+	fields := &structFields{byName: make(map[string]reflect.Type)}
+	fields.add(t)
+	f, _ := structFieldsCache.LoadOrStore(t, fields)
+	return f.(*structFields)
+}
+
+func (fields *structFields) add(t reflect.Type) {
+	if t.PkgPath() == reflect.TypeOf(Codec{}).PkgPath() {
+		for _, name := range requiredProperties[t.Name()] {
+			if !contains(fields.required, name) {
+				fields.required = append(fields.required, name)
+			}
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			fields.add(f.Type)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields.byName[name] = f.Type
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains the types of properties that the specification allows
+// to have values of several types, and helpers for properties whose values
+// are defined by the debug adapter rather than the specification.
+
+package dap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// IntOrString is the value of a property that is either an integer or a
+// string, such as Module.Id and StackFrame.ModuleId. The zero value is the
+// empty string.
+type IntOrString struct {
+	s     string
+	i     int
+	isInt bool
+}
+
+// IntOrStringFromInt returns an IntOrString holding integer i.
+func IntOrStringFromInt(i int) IntOrString {
+	return IntOrString{i: i, isInt: true}
+}
+
+// IntOrStringFromString returns an IntOrString holding string s.
+func IntOrStringFromString(s string) IntOrString {
+	return IntOrString{s: s}
+}
+
+// AsInt returns the integer v holds, and whether it holds an integer.
+func (v IntOrString) AsInt() (int, bool) {
+	return v.i, v.isInt
+}
+
+// AsString returns the string v holds, and whether it holds a string.
+func (v IntOrString) AsString() (string, bool) {
+	return v.s, !v.isInt
+}
+
+// String returns the string v holds, or the decimal representation of the
+// integer it holds.
+func (v IntOrString) String() string {
+	if v.isInt {
+		return strconv.Itoa(v.i)
+	}
+	return v.s
+}
+
+func (v IntOrString) MarshalJSON() ([]byte, error) {
+	if v.isInt {
+		return strconv.AppendInt(nil, int64(v.i), 10), nil
+	}
+	return json.Marshal(v.s)
+}
+
+func (v *IntOrString) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*v = IntOrStringFromString(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil || n == "" {
+		return fmt.Errorf("want integer or string, got %s", data)
+	}
+	// Integers are parsed from their text, rather than through a float64,
+	// so that they keep their precision. Integral numbers written in other
+	// forms, such as 1.0 or 1e2 by encoders that only have floats, are
+	// accepted too.
+	if i, err := strconv.ParseInt(n.String(), 10, 0); err == nil {
+		*v = IntOrStringFromInt(int(i))
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil || f != math.Trunc(f) || f < math.MinInt || f >= -math.MinInt {
+		return fmt.Errorf("want integer or string, got %s", data)
+	}
+	*v = IntOrStringFromInt(int(f))
+	return nil
+}
+
+// NullableString is the value of a property that is either a string or
+// null, such as DataBreakpointInfoResponseBody.DataId. The zero value is
+// null.
+type NullableString struct {
+	s     string
+	valid bool
+}
+
+// NullableStringFromString returns a NullableString holding string s.
+func NullableStringFromString(s string) NullableString {
+	return NullableString{s: s, valid: true}
+}
+
+// AsString returns the string v holds, and whether it is not null.
+func (v NullableString) AsString() (string, bool) {
+	return v.s, v.valid
+}
+
+// IsNull reports whether v is null.
+func (v NullableString) IsNull() bool {
+	return !v.valid
+}
+
+func (v NullableString) MarshalJSON() ([]byte, error) {
+	if !v.valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(v.s)
+}
+
+func (v *NullableString) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*v = NullableString{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("want string or null, got %s", data)
+	}
+	*v = NullableStringFromString(s)
+	return nil
+}
+
+// SetRestart sets Restart to the JSON encoding of v, which the client
+// passes back in the __restart property of the launch or attach request
+// of the restarted session.
+func (b *TerminatedEventBody) SetRestart(v any) error {
+	return setRaw(&b.Restart, v)
+}
+
+// DecodeRestart decodes Restart into v, as json.Unmarshal does. It leaves v
+// unchanged if Restart is absent.
+func (b *TerminatedEventBody) DecodeRestart(v any) error {
+	return decodeRaw(b.Restart, v)
+}
+
+// SetAdapterData sets AdapterData to the JSON encoding of v, which the
+// client passes back along with the source.
+func (s *Source) SetAdapterData(v any) error {
+	return setRaw(&s.AdapterData, v)
+}
+
+// DecodeAdapterData decodes AdapterData into v, as json.Unmarshal does. It
+// leaves v unchanged if AdapterData is absent.
+func (s *Source) DecodeAdapterData(v any) error {
+	return decodeRaw(s.AdapterData, v)
+}
+
+// DecodeArguments decodes the latest launch or attach arguments, which the
+// client may pass to the restart request, into v, as json.Unmarshal does.
+// It leaves v unchanged if they are absent.
+func (r *RestartRequest) DecodeArguments(v any) error {
+	var args struct {
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := decodeRaw(r.Arguments, &args); err != nil {
+		return err
+	}
+	return decodeRaw(args.Arguments, v)
+}
+
+// DecodeRestartData decodes the __restart property of the arguments of r
+// into v, as json.Unmarshal does. The property is set by the client to the
+// restart data of the terminated event that caused the session to restart;
+// see TerminatedEventBody.SetRestart. It leaves v unchanged if the property
+// is absent.
+func DecodeRestartData(r LaunchAttachRequest, v any) error {
+	var args struct {
+		Restart json.RawMessage `json:"__restart"`
+	}
+	if err := decodeRaw(r.GetArguments(), &args); err != nil {
+		return err
+	}
+	return decodeRaw(args.Restart, v)
+}
+
+// setRaw sets *raw to the JSON encoding of v.
+func setRaw(raw *json.RawMessage, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	*raw = b
+	return nil
+}
+
+// decodeRaw decodes raw into v unless raw is empty.
+func decodeRaw(raw json.RawMessage, v any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
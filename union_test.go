@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dap
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestIntOrString(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    IntOrString
+		wantErr bool
+		// encoded is the encoding of want, if it is not data.
+		encoded string
+	}{
+		{data: `42`, want: IntOrStringFromInt(42)},
+		{data: `9007199254740993`, want: IntOrStringFromInt(9007199254740993)},
+		{data: `-1`, want: IntOrStringFromInt(-1)},
+		{data: `"42"`, want: IntOrStringFromString("42")},
+		{data: `""`, want: IntOrString{}},
+		{data: `1.0`, want: IntOrStringFromInt(1), encoded: `1`},
+		{data: `1e2`, want: IntOrStringFromInt(100), encoded: `100`},
+		{data: `-2.5e1`, want: IntOrStringFromInt(-25), encoded: `-25`},
+		{data: `1.5`, wantErr: true},
+		{data: `1e300`, wantErr: true},
+		{data: `null`, wantErr: true},
+		{data: `true`, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.data, func(t *testing.T) {
+			var got IntOrString
+			err := json.Unmarshal([]byte(test.data), &got)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %#v, want error", got)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Fatalf("got %#v, err=%v, want %#v", got, err, test.want)
+			}
+			want := test.encoded
+			if want == "" {
+				want = test.data
+			}
+			data, err := json.Marshal(got)
+			if err != nil || string(data) != want {
+				t.Errorf("got %s, err=%v, want %s", data, err, want)
+			}
+		})
+	}
+
+	v := IntOrStringFromInt(7)
+	if i, ok := v.AsInt(); !ok || i != 7 {
+		t.Errorf("AsInt() = %d, %v, want 7, true", i, ok)
+	}
+	if _, ok := v.AsString(); ok || v.String() != "7" {
+		t.Errorf("AsString() ok = %v, String() = %q, want false, \"7\"", ok, v.String())
+	}
+
+	// An optional IntOrString is omitted if nil.
+	data, err := json.Marshal(StackFrame{Id: 1, Name: "main"})
+	if err != nil || string(data) != `{"id":1,"name":"main","line":0,"column":0}` {
+		t.Errorf("got %s, err=%v", data, err)
+	}
+}
+
+func TestNullableString(t *testing.T) {
+	var body DataBreakpointInfoResponseBody
+	if err := json.Unmarshal([]byte(`{"dataId":null,"description":"d"}`), &body); err != nil || !body.DataId.IsNull() {
+		t.Fatalf("got %#v, err=%v, want null dataId", body, err)
+	}
+	if err := json.Unmarshal([]byte(`{"dataId":"x","description":"d"}`), &body); err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := body.DataId.AsString(); !ok || s != "x" {
+		t.Errorf("AsString() = %q, %v, want \"x\", true", s, ok)
+	}
+	if err := json.Unmarshal([]byte(`{"dataId":1}`), &body); err == nil {
+		t.Error("got nil error for a number, want error")
+	}
+
+	args := RunInTerminalRequestArguments{Env: map[string]NullableString{"A": NullableStringFromString("1"), "B": {}}}
+	data, err := json.Marshal(args.Env)
+	if err != nil || string(data) != `{"A":"1","B":null}` {
+		t.Errorf("got %s, err=%v", data, err)
+	}
+}
+
+func TestRawHelpers(t *testing.T) {
+	type data struct {
+		Token string `json:"token"`
+	}
+	want := data{Token: "t"}
+
+	var terminated TerminatedEventBody
+	var got data
+	if err := terminated.DecodeRestart(&got); err != nil || got != (data{}) {
+		t.Errorf("got %#v, err=%v decoding absent restart", got, err)
+	}
+	if err := terminated.SetRestart(want); err != nil {
+		t.Fatal(err)
+	}
+	if err := terminated.DecodeRestart(&got); err != nil || got != want {
+		t.Errorf("got %#v, err=%v, want %#v", got, err, want)
+	}
+
+	// The client passes the restart data back when it launches again.
+	launch := &LaunchRequest{Arguments: json.RawMessage(`{"program":"a.out","__restart":` + string(terminated.Restart) + `}`)}
+	got = data{}
+	if err := DecodeRestartData(launch, &got); err != nil || got != want {
+		t.Errorf("got %#v, err=%v, want %#v", got, err, want)
+	}
+
+	var source Source
+	if err := source.SetAdapterData([]int{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	var ints []int
+	if err := source.DecodeAdapterData(&ints); err != nil || !reflect.DeepEqual(ints, []int{1, 2}) {
+		t.Errorf("got %v, err=%v, want [1 2]", ints, err)
+	}
+
+	restart := &RestartRequest{Arguments: json.RawMessage(`{"arguments":{"program":"b.out"}}`)}
+	var launchArgs struct {
+		Program string `json:"program"`
+	}
+	if err := restart.DecodeArguments(&launchArgs); err != nil || launchArgs.Program != "b.out" {
+		t.Errorf("got %#v, err=%v, want program b.out", launchArgs, err)
+	}
+}